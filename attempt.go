@@ -0,0 +1,33 @@
+//information about the current attempt of gover
+//it's stored in the context given to the job
+package gover
+
+import (
+	"context"
+	"time"
+)
+
+//the attempt of the job that's currently running
+type Attempt struct {
+	//number of the attempt, starting from 1
+	Number int
+	//when the attempt is timed out, zero if it has no deadline
+	Deadline time.Time
+	//the error of the previous attempt, nil on the first one
+	PreviousErr error
+}
+
+//key of the attempt in the context
+type attemptKey struct{}
+
+//add the attempt into the context of the job
+func withAttempt(ctx context.Context, attempt Attempt) context.Context {
+	return context.WithValue(ctx, attemptKey{}, attempt)
+}
+
+//get the attempt from the context given to the job
+//return false if the job is not run by gover
+func AttemptFromContext(ctx context.Context) (Attempt, bool) {
+	attempt, ok := ctx.Value(attemptKey{}).(Attempt)
+	return attempt, ok
+}
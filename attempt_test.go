@@ -0,0 +1,72 @@
+package gover
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestAttemptFromContext(t *testing.T) {
+	_, ok := AttemptFromContext(context.Background())
+	assert.Equal(t, false, ok)
+
+	var attempts []Attempt
+	job := func(ctx context.Context) error {
+		attempt, ok := AttemptFromContext(ctx)
+		assert.Equal(t, true, ok)
+		attempts = append(attempts, attempt)
+		if attempt.Number < 3 {
+			return fmt.Errorf("try %d", attempt.Number)
+		}
+		return nil
+	}
+
+	gover, _ := New(job, WithTimeout(time.Hour))
+	gover.MaxRetry = 3
+	gover.JobInterval = "1m"
+	start := time.Now()
+	assert.NoError(t, gover.Run(context.Background()))
+
+	assert.Equal(t, 3, len(attempts))
+	for i, attempt := range attempts {
+		assert.Equal(t, i+1, attempt.Number)
+		assert.WithinDuration(t, start.Add(time.Minute), attempt.Deadline, time.Second)
+	}
+	assert.Nil(t, attempts[0].PreviousErr)
+	assert.Equal(t, "try 1", attempts[1].PreviousErr.Error())
+	assert.Equal(t, "try 2", attempts[2].PreviousErr.Error())
+}
+
+func TestAttemptTimeout(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+
+	//the first attempt waits until its context is done
+	finished := make(chan error, 1)
+	job := func(ctx context.Context) error {
+		attempt, _ := AttemptFromContext(ctx)
+		if attempt.Number == 1 {
+			<-ctx.Done()
+			finished <- ctx.Err()
+			return ctx.Err()
+		}
+		return nil
+	}
+
+	gover, _ := New(job, WithTimeout(time.Hour), WithClock(clock))
+	gover.MaxRetry = 1
+	gover.JobInterval = "1m"
+	gover.RetryInterval = "0s"
+
+	result := make(chan error, 1)
+	go func() { result <- gover.Run(context.Background()) }()
+
+	//the deadline of the run and of the attempt are waiting
+	clock.BlockUntil(2)
+	clock.Advance(time.Minute)
+	assert.NoError(t, <-result)
+
+	//the job knows that its attempt is timed out
+	assert.Equal(t, context.DeadlineExceeded, <-finished)
+}
@@ -0,0 +1,82 @@
+//backoff strategies decide how long gover waits before each retry
+//a fixed interval makes all clients retry at the same time, so the jitter strategies spread them randomly
+package gover
+
+import (
+	"math/rand"
+	"time"
+)
+
+//return how long to wait before the retry with the given number (starting from 1)
+//the previous delay is the one returned for the previous retry, 0 for the first one
+type BackoffStrategy func(retry int, previous time.Duration) time.Duration
+
+//always wait the same duration
+func ConstantBackoff(interval time.Duration) BackoffStrategy {
+	return func(int, time.Duration) time.Duration {
+		return interval
+	}
+}
+
+//wait the initial duration and add the increment for every following retry
+//e.g. 1s, 3s, 5s, 7s for initial 1s and increment 2s
+func LinearBackoff(initial, increment time.Duration) BackoffStrategy {
+	return func(retry int, _ time.Duration) time.Duration {
+		return initial + increment*time.Duration(retry-1)
+	}
+}
+
+//double the waiting duration on every retry, starting from base, but never longer than max
+//e.g. 1s, 2s, 4s, 8s for base 1s
+func ExponentialBackoff(base, max time.Duration) BackoffStrategy {
+	return func(retry int, _ time.Duration) time.Duration {
+		return exponential(base, max, retry)
+	}
+}
+
+//wait a random duration between 0 and the exponential backoff
+func FullJitterBackoff(base, max time.Duration) BackoffStrategy {
+	return func(retry int, _ time.Duration) time.Duration {
+		return randomBetween(0, exponential(base, max, retry))
+	}
+}
+
+//wait a random duration between base and three times the previous delay, but never longer than max
+//the delays grow about as fast as the exponential backoff, but they don't depend on the retry number
+func DecorrelatedJitterBackoff(base, max time.Duration) BackoffStrategy {
+	return func(_ int, previous time.Duration) time.Duration {
+		if previous < base {
+			previous = base
+		}
+		//avoid the overflow, the result is capped anyway
+		upper := max
+		if previous < max/3 {
+			upper = previous * 3
+		}
+		return randomBetween(base, upper)
+	}
+}
+
+//base * 2^(retry-1) capped on max
+func exponential(base, max time.Duration, retry int) time.Duration {
+	result := base
+	for i := 1; i < retry; i++ {
+		//stop doubling before it's overflowing
+		if result >= max/2 {
+			return max
+		}
+		result *= 2
+	}
+	if result > max {
+		return max
+	}
+	return result
+}
+
+//random duration in [min, max), min if the range is empty
+func randomBetween(min, max time.Duration) time.Duration {
+	if max <= min {
+		return min
+	}
+	return min + time.Duration(rand.Int63n(int64(max-min)))
+}
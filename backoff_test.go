@@ -0,0 +1,81 @@
+package gover
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestBackoffStrategies(t *testing.T) {
+	constant := ConstantBackoff(time.Second)
+	assert.Equal(t, time.Second, constant(1, 0))
+	assert.Equal(t, time.Second, constant(10, time.Second))
+
+	linear := LinearBackoff(time.Second, time.Second*2)
+	assert.Equal(t, time.Second, linear(1, 0))
+	assert.Equal(t, time.Second*3, linear(2, time.Second))
+	assert.Equal(t, time.Second*7, linear(4, time.Second*5))
+
+	exp := ExponentialBackoff(time.Second, time.Minute)
+	assert.Equal(t, time.Second, exp(1, 0))
+	assert.Equal(t, time.Second*2, exp(2, 0))
+	assert.Equal(t, time.Second*32, exp(6, 0))
+	assert.Equal(t, time.Minute, exp(7, 0))
+	assert.Equal(t, time.Minute, exp(1000, 0))
+
+	//no overflow even with the largest duration
+	huge := ExponentialBackoff(time.Second, time.Duration(math.MaxInt64))
+	assert.Equal(t, time.Duration(math.MaxInt64), huge(100, 0))
+
+	full := FullJitterBackoff(time.Second, time.Minute)
+	for retry := 1; retry <= 10; retry++ {
+		for i := 0; i < 100; i++ {
+			delay := full(retry, 0)
+			assert.Equal(t, true, delay >= 0 && delay < exp(retry, 0), delay)
+		}
+	}
+
+	decorrelated := DecorrelatedJitterBackoff(time.Second, time.Minute)
+	previous := time.Duration(0)
+	for i := 0; i < 1000; i++ {
+		delay := decorrelated(i+1, previous)
+		assert.Equal(t, true, delay >= time.Second && delay <= time.Minute, delay)
+		if previous >= time.Second {
+			assert.Equal(t, true, delay < previous*3, delay)
+		}
+		previous = delay
+	}
+}
+
+func TestGoverWithBackoff(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	start := clock.Now()
+
+	var tries []time.Time
+	job := func(ctx context.Context) error {
+		tries = append(tries, clock.Now())
+		if len(tries) < 4 {
+			return fmt.Errorf("not yet")
+		}
+		return nil
+	}
+
+	gover, _ := New(job, WithTimeout(time.Hour), WithClock(clock))
+	gover.MaxRetry = 3
+	gover.RetryInterval = "1h"
+	gover.Backoff = ExponentialBackoff(time.Second, time.Minute)
+
+	result := make(chan error, 1)
+	go func() { result <- gover.Run(context.Background()) }()
+
+	//the retry interval is ignored, the waiting duration doubles on every retry
+	for _, delay := range []time.Duration{time.Second, time.Second * 2, time.Second * 4} {
+		clock.BlockUntil(2)
+		clock.Advance(delay)
+	}
+	assert.NoError(t, <-result)
+	assert.Equal(t, []time.Time{start, start.Add(time.Second), start.Add(time.Second * 3), start.Add(time.Second * 7)}, tries)
+}
@@ -0,0 +1,137 @@
+//bulkhead limits how many attempts of the jobs can run at once
+//it can be shared by many govers, the excess attempts wait in the queue or are rejected with ErrBulkheadFull
+package gover
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+//the configuration of the bulkhead
+type BulkheadConfig struct {
+	//maximum number of the attempts that can run at once
+	MaxConcurrent int
+	//maximum number of the attempts that can wait for a free slot, 0 means they are rejected immediately
+	MaxQueue int
+	//how long an attempt can wait in the queue, 0 means until the context of the run is done
+	QueueTimeout time.Duration
+	//source of the time that decides when the queue timeout is over, nil means the system clock
+	Clock Clock
+}
+
+//make sure that the configuration can be used by the bulkhead
+func (bc BulkheadConfig) validate() error {
+	if bc.MaxConcurrent <= 0 {
+		return fmt.Errorf("Invalid maximum number of concurrent attempts: %d", bc.MaxConcurrent)
+	}
+	if bc.MaxQueue < 0 {
+		return fmt.Errorf("Invalid maximum size of the queue: %d", bc.MaxQueue)
+	}
+	if bc.QueueTimeout < 0 {
+		return fmt.Errorf("Invalid queue timeout: %s", bc.QueueTimeout)
+	}
+	return nil
+}
+
+//the bulkhead that's attached to gover with WithBulkhead
+//every attempt takes a slot before it starts and gives it back once the job returns
+//the timed out attempt keeps its slot until the job really returns, since it's still running
+//it's safe for concurrent use
+type Bulkhead struct {
+	settings BulkheadConfig
+	clock    Clock
+	//the slots, an attempt holds one while it's running
+	slots chan struct{}
+
+	mu     sync.Mutex
+	queued int
+}
+
+//create the bulkhead with the configuration, all slots are free at first
+//return error if the configuration is not valid
+func NewBulkhead(settings BulkheadConfig) (*Bulkhead, error) {
+	if err := settings.validate(); err != nil {
+		return nil, err
+	}
+	if settings.Clock == nil {
+		settings.Clock = realClock{}
+	}
+
+	return &Bulkhead{
+		settings: settings,
+		clock:    settings.Clock,
+		slots:    make(chan struct{}, settings.MaxConcurrent),
+	}, nil
+}
+
+//limit the attempts of gover with the bulkhead, the rejected attempt stops the run with ErrBulkheadFull
+func WithBulkhead(bulkhead *Bulkhead) Option {
+	return func(c *config) error {
+		if bulkhead == nil {
+			return fmt.Errorf("Please input a valid bulkhead")
+		}
+		c.bulkhead = bulkhead
+		c.retried("WithBulkhead")
+		return nil
+	}
+}
+
+//number of the attempts that are currently running
+func (b *Bulkhead) InFlight() int {
+	return len(b.slots)
+}
+
+//number of the attempts that are currently waiting in the queue
+func (b *Bulkhead) Queued() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.queued
+}
+
+//take a slot for the attempt
+//wait in the queue if it's allowed, otherwise return ErrBulkheadFull immediately if there's no free slot
+//return ErrBulkheadFull if the queue is full or the queue timeout is over, the context error if it's done first
+func (b *Bulkhead) acquire(ctx context.Context, wait bool) error {
+	select {
+	case b.slots <- struct{}{}:
+		return nil
+	default:
+	}
+
+	b.mu.Lock()
+	if !wait || b.queued >= b.settings.MaxQueue {
+		b.mu.Unlock()
+		return ErrBulkheadFull
+	}
+	b.queued++
+	b.mu.Unlock()
+
+	defer func() {
+		b.mu.Lock()
+		b.queued--
+		b.mu.Unlock()
+	}()
+
+	var timeout <-chan time.Time
+	if b.settings.QueueTimeout > 0 {
+		timer := b.clock.NewTimer(b.settings.QueueTimeout)
+		defer timer.Stop()
+		timeout = timer.C()
+	}
+
+	select {
+	case b.slots <- struct{}{}:
+		return nil
+	case <-timeout:
+		return ErrBulkheadFull
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+//give back the slot of the finished attempt
+func (b *Bulkhead) release() {
+	<-b.slots
+}
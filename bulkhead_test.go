@@ -0,0 +1,98 @@
+package gover
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestBulkheadConfig(t *testing.T) {
+	for _, settings := range []BulkheadConfig{
+		{},
+		{MaxConcurrent: 1, MaxQueue: -1},
+		{MaxConcurrent: 1, QueueTimeout: -time.Second},
+	} {
+		_, err := NewBulkhead(settings)
+		assert.Error(t, err, settings)
+	}
+
+	_, err := New(func(ctx context.Context) error { return nil }, WithBulkhead(nil))
+	assert.Error(t, err)
+}
+
+func TestBulkhead(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	bulkhead, err := NewBulkhead(BulkheadConfig{MaxConcurrent: 1, MaxQueue: 1, QueueTimeout: time.Second, Clock: clock})
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	assert.NoError(t, bulkhead.acquire(ctx, true))
+	assert.Equal(t, 1, bulkhead.InFlight())
+
+	//the next one waits in the queue until the slot is free
+	acquired := make(chan error, 1)
+	go func() { acquired <- bulkhead.acquire(ctx, true) }()
+	clock.BlockUntil(1)
+	assert.Equal(t, 1, bulkhead.Queued())
+
+	//the queue is full and the hedged attempt doesn't wait at all
+	assert.Equal(t, ErrBulkheadFull, bulkhead.acquire(ctx, true))
+	assert.Equal(t, ErrBulkheadFull, bulkhead.acquire(ctx, false))
+
+	bulkhead.release()
+	assert.NoError(t, <-acquired)
+	assert.Equal(t, 0, bulkhead.Queued())
+
+	//the waiting is over after the queue timeout
+	go func() { acquired <- bulkhead.acquire(ctx, true) }()
+	clock.BlockUntil(1)
+	clock.Advance(time.Second)
+	assert.Equal(t, ErrBulkheadFull, <-acquired)
+
+	//or once the context is done
+	cancelled, cancel := context.WithCancel(ctx)
+	go func() { acquired <- bulkhead.acquire(cancelled, true) }()
+	clock.BlockUntil(1)
+	cancel()
+	assert.Equal(t, context.Canceled, <-acquired)
+
+	bulkhead.release()
+	assert.Equal(t, 0, bulkhead.InFlight())
+}
+
+func TestGoverBulkhead(t *testing.T) {
+	bulkhead, _ := NewBulkhead(BulkheadConfig{MaxConcurrent: 1})
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	blocking := func(ctx context.Context) error {
+		close(started)
+		<-release
+		return nil
+	}
+	tryNum := 0
+	job := func(ctx context.Context) error {
+		tryNum++
+		return nil
+	}
+
+	first, err := New(blocking, WithBulkhead(bulkhead))
+	assert.NoError(t, err)
+	result := make(chan error, 1)
+	go func() { result <- first.Run(context.Background()) }()
+	<-started
+
+	//the other gover is rejected while the slot is taken
+	second, _ := New(job, WithBulkhead(bulkhead), WithMaxRetry(3))
+	err = second.Run(context.Background())
+	assert.Equal(t, true, errors.Is(err, ErrBulkheadFull))
+	assert.Equal(t, 0, tryNum)
+
+	close(release)
+	assert.NoError(t, <-result)
+	assert.Equal(t, 0, bulkhead.InFlight())
+	assert.NoError(t, second.Run(context.Background()))
+	assert.Equal(t, 1, tryNum)
+}
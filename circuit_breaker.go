@@ -0,0 +1,280 @@
+//circuit breaker stops calling a downstream that keeps failing
+//it can be shared by many govers, so all of them fail fast with ErrCircuitOpen instead of burning their retries
+package gover
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+//state of the circuit breaker
+type CircuitState int
+
+const (
+	//every attempt is allowed, the failures are counted
+	CircuitClosed CircuitState = iota
+	//no attempt is allowed until the cool-down is over
+	CircuitOpen
+	//only a few probe attempts are allowed to check whether the downstream is back
+	CircuitHalfOpen
+)
+
+func (cs CircuitState) String() string {
+	switch cs {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return fmt.Sprintf("CircuitState(%d)", int(cs))
+}
+
+//the configuration of the circuit breaker
+//at least one of ConsecutiveFailures or FailureRate has to be set
+type CircuitBreakerConfig struct {
+	//open the circuit after this number of failures in a row, 0 means it's not checked
+	ConsecutiveFailures int
+	//open the circuit if the rate of the failures reaches it (between 0 and 1), 0 means it's not checked
+	//the rate is only checked once there are at least Window attempts
+	FailureRate float64
+	//number of the latest attempts in the closed state that the failure rate is calculated from
+	Window int
+	//how long the circuit stays open before the probe attempts are allowed
+	CoolDown time.Duration
+	//number of probe attempts in the half-open state, default is 1
+	//the circuit is closed if all of them are successful and opened again on the first failure
+	HalfOpenProbes int
+	//called after the state is changed
+	//it's called outside the lock, so it can read the state of the breaker
+	OnStateChange func(from, to CircuitState)
+	//source of the time that decides when the cool-down is over, nil means the system clock
+	Clock Clock
+}
+
+//make sure that the configuration can be used by the circuit breaker
+func (cc CircuitBreakerConfig) validate() error {
+	if cc.ConsecutiveFailures < 0 {
+		return fmt.Errorf("Invalid number of consecutive failures: %d", cc.ConsecutiveFailures)
+	}
+	if cc.FailureRate < 0 || cc.FailureRate > 1 {
+		return fmt.Errorf("Invalid failure rate: %v", cc.FailureRate)
+	}
+	if cc.ConsecutiveFailures == 0 && cc.FailureRate == 0 {
+		return fmt.Errorf("Please input the number of consecutive failures or the failure rate")
+	}
+	if cc.FailureRate > 0 && cc.Window <= 0 {
+		return fmt.Errorf("Invalid window of the failure rate: %d", cc.Window)
+	}
+	if cc.CoolDown <= 0 {
+		return fmt.Errorf("Cool-down %s is too short", cc.CoolDown)
+	}
+	if cc.HalfOpenProbes < 0 {
+		return fmt.Errorf("Invalid number of half-open probes: %d", cc.HalfOpenProbes)
+	}
+	return nil
+}
+
+//a state change that's not yet given to OnStateChange
+type circuitChange struct {
+	from, to CircuitState
+}
+
+//the circuit breaker that's attached to gover with WithCircuitBreaker
+//every attempt of the job asks the breaker first and reports its result afterwards
+//it's safe for concurrent use
+type CircuitBreaker struct {
+	settings CircuitBreakerConfig
+	clock    Clock
+
+	mu    sync.Mutex
+	state CircuitState
+	//increased on every state change, so the results of the older attempts are ignored
+	generation uint64
+	//when the circuit is opened
+	openedAt time.Time
+	//the failures in a row in the closed state
+	consecutive int
+	//the results of the latest attempts in the closed state, true means failure
+	window   []bool
+	next     int
+	failures int
+	//probes that are allowed and successful in the half-open state
+	probes    int
+	successes int
+	//the changes that have to be given to OnStateChange after unlocking
+	changes []circuitChange
+}
+
+//create the circuit breaker with the configuration, the circuit starts closed
+//return error if the configuration is not valid
+func NewCircuitBreaker(settings CircuitBreakerConfig) (*CircuitBreaker, error) {
+	if err := settings.validate(); err != nil {
+		return nil, err
+	}
+	if settings.HalfOpenProbes == 0 {
+		settings.HalfOpenProbes = 1
+	}
+	if settings.Clock == nil {
+		settings.Clock = realClock{}
+	}
+
+	cb := &CircuitBreaker{settings: settings, clock: settings.Clock}
+	if settings.FailureRate > 0 {
+		cb.window = make([]bool, 0, settings.Window)
+	}
+	return cb, nil
+}
+
+//attach the circuit breaker to gover, every attempt is rejected with ErrCircuitOpen while it's open
+func WithCircuitBreaker(cb *CircuitBreaker) Option {
+	return func(c *config) error {
+		if cb == nil {
+			return fmt.Errorf("Please input a valid circuit breaker")
+		}
+		c.circuitBreaker = cb
+		c.retried("WithCircuitBreaker")
+		return nil
+	}
+}
+
+//the current state of the circuit breaker
+func (cb *CircuitBreaker) State() CircuitState {
+	var state CircuitState
+	cb.update(func(now time.Time) {
+		state = cb.state
+	})
+	return state
+}
+
+//check whether an attempt can start
+//return the generation that has to be given back with the result or ErrCircuitOpen
+func (cb *CircuitBreaker) allow() (uint64, error) {
+	var generation uint64
+	var err error
+	cb.update(func(now time.Time) {
+		generation = cb.generation
+		switch cb.state {
+		case CircuitOpen:
+			err = ErrCircuitOpen
+		case CircuitHalfOpen:
+			if cb.probes >= cb.settings.HalfOpenProbes {
+				err = ErrCircuitOpen
+				return
+			}
+			cb.probes++
+		}
+	})
+	return generation, err
+}
+
+//record the result of the allowed attempt
+func (cb *CircuitBreaker) record(generation uint64, success bool) {
+	cb.update(func(now time.Time) {
+		if generation != cb.generation {
+			return
+		}
+
+		switch cb.state {
+		case CircuitClosed:
+			if success {
+				cb.consecutive = 0
+			} else {
+				cb.consecutive++
+			}
+			cb.addToWindow(!success)
+			if cb.shouldOpen() {
+				cb.setState(CircuitOpen, now)
+			}
+		case CircuitHalfOpen:
+			if !success {
+				cb.setState(CircuitOpen, now)
+				return
+			}
+			cb.successes++
+			if cb.successes >= cb.settings.HalfOpenProbes {
+				cb.setState(CircuitClosed, now)
+			}
+		}
+	})
+}
+
+//give back the allowed attempt without any result, e.g. if the run is cancelled
+func (cb *CircuitBreaker) release(generation uint64) {
+	cb.update(func(now time.Time) {
+		if generation == cb.generation && cb.state == CircuitHalfOpen {
+			cb.probes--
+		}
+	})
+}
+
+//run the function with the lock after the cool-down is checked
+//the state changes are given to OnStateChange after unlocking
+func (cb *CircuitBreaker) update(fn func(now time.Time)) {
+	cb.mu.Lock()
+	now := cb.clock.Now()
+	if cb.state == CircuitOpen && !now.Before(cb.openedAt.Add(cb.settings.CoolDown)) {
+		cb.setState(CircuitHalfOpen, now)
+	}
+	fn(now)
+	changes := cb.changes
+	cb.changes = nil
+	cb.mu.Unlock()
+
+	if cb.settings.OnStateChange != nil {
+		for _, change := range changes {
+			cb.settings.OnStateChange(change.from, change.to)
+		}
+	}
+}
+
+//add the result into the window of the failure rate
+func (cb *CircuitBreaker) addToWindow(failure bool) {
+	if cb.window == nil {
+		return
+	}
+	if len(cb.window) < cap(cb.window) {
+		cb.window = append(cb.window, failure)
+	} else {
+		if cb.window[cb.next] {
+			cb.failures--
+		}
+		cb.window[cb.next] = failure
+	}
+	cb.next = (cb.next + 1) % cap(cb.window)
+	if failure {
+		cb.failures++
+	}
+}
+
+//check the thresholds of the closed state
+func (cb *CircuitBreaker) shouldOpen() bool {
+	if cb.settings.ConsecutiveFailures > 0 && cb.consecutive >= cb.settings.ConsecutiveFailures {
+		return true
+	}
+	if cb.window != nil && len(cb.window) == cap(cb.window) {
+		return float64(cb.failures)/float64(len(cb.window)) >= cb.settings.FailureRate
+	}
+	return false
+}
+
+//change the state and reset the counters
+func (cb *CircuitBreaker) setState(state CircuitState, now time.Time) {
+	if state == cb.state {
+		return
+	}
+	cb.changes = append(cb.changes, circuitChange{from: cb.state, to: state})
+	cb.state = state
+	cb.generation++
+	cb.openedAt = now
+	cb.consecutive = 0
+	if cb.window != nil {
+		cb.window = cb.window[:0]
+	}
+	cb.next = 0
+	cb.failures = 0
+	cb.probes = 0
+	cb.successes = 0
+}
@@ -0,0 +1,153 @@
+package gover
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreakerConfig(t *testing.T) {
+	for _, settings := range []CircuitBreakerConfig{
+		{CoolDown: time.Second},
+		{ConsecutiveFailures: -1, CoolDown: time.Second},
+		{FailureRate: 1.5, Window: 10, CoolDown: time.Second},
+		{FailureRate: 0.5, CoolDown: time.Second},
+		{ConsecutiveFailures: 3},
+		{ConsecutiveFailures: 3, CoolDown: time.Second, HalfOpenProbes: -1},
+	} {
+		_, err := NewCircuitBreaker(settings)
+		assert.Error(t, err, settings)
+	}
+
+	_, err := New(func(ctx context.Context) error { return nil }, WithCircuitBreaker(nil))
+	assert.Error(t, err)
+
+	assert.Equal(t, "half-open", CircuitHalfOpen.String())
+	assert.Equal(t, "CircuitState(5)", CircuitState(5).String())
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+
+	var changes []string
+	cb, err := NewCircuitBreaker(CircuitBreakerConfig{
+		ConsecutiveFailures: 3,
+		CoolDown:            time.Minute,
+		HalfOpenProbes:      2,
+		OnStateChange: func(from, to CircuitState) {
+			changes = append(changes, fmt.Sprintf("%s->%s", from, to))
+		},
+		Clock: clock,
+	})
+	assert.NoError(t, err)
+
+	attempt := func(success bool) error {
+		generation, err := cb.allow()
+		if err == nil {
+			cb.record(generation, success)
+		}
+		return err
+	}
+
+	//the success resets the consecutive failures
+	assert.NoError(t, attempt(false))
+	assert.NoError(t, attempt(false))
+	assert.NoError(t, attempt(true))
+	assert.NoError(t, attempt(false))
+	assert.NoError(t, attempt(false))
+	assert.Equal(t, CircuitClosed, cb.State())
+	assert.NoError(t, attempt(false))
+	assert.Equal(t, CircuitOpen, cb.State())
+	assert.Equal(t, ErrCircuitOpen, attempt(true))
+
+	//the probes are allowed after the cool-down, the failed one opens the circuit again
+	clock.Advance(time.Minute)
+	assert.Equal(t, CircuitHalfOpen, cb.State())
+	assert.NoError(t, attempt(false))
+	assert.Equal(t, CircuitOpen, cb.State())
+
+	//only the given number of probes can run at once
+	clock.Advance(time.Minute)
+	first, err := cb.allow()
+	assert.NoError(t, err)
+	second, err := cb.allow()
+	assert.NoError(t, err)
+	_, err = cb.allow()
+	assert.Equal(t, ErrCircuitOpen, err)
+
+	//the released probe can be taken again
+	cb.release(second)
+	second, err = cb.allow()
+	assert.NoError(t, err)
+
+	//the circuit is closed once all probes are successful
+	cb.record(first, true)
+	assert.Equal(t, CircuitHalfOpen, cb.State())
+	cb.record(second, true)
+	assert.Equal(t, CircuitClosed, cb.State())
+
+	//the results of the older state are ignored
+	cb.record(first, false)
+	cb.record(first, false)
+	cb.record(first, false)
+	assert.Equal(t, CircuitClosed, cb.State())
+
+	assert.Equal(t, []string{
+		"closed->open", "open->half-open", "half-open->open", "open->half-open", "half-open->closed",
+	}, changes)
+}
+
+func TestCircuitBreakerFailureRate(t *testing.T) {
+	settings := CircuitBreakerConfig{FailureRate: 0.75, Window: 4, CoolDown: time.Minute}
+	record := func(cb *CircuitBreaker, results ...bool) {
+		for _, success := range results {
+			generation, err := cb.allow()
+			assert.NoError(t, err)
+			cb.record(generation, success)
+		}
+	}
+
+	//the rate is checked only if the window is full
+	cb, err := NewCircuitBreaker(settings)
+	assert.NoError(t, err)
+	record(cb, false, false, false)
+	assert.Equal(t, CircuitClosed, cb.State())
+	record(cb, true)
+	assert.Equal(t, CircuitOpen, cb.State())
+
+	//the oldest result leaves the window
+	cb, _ = NewCircuitBreaker(settings)
+	record(cb, true, false, false, true)
+	assert.Equal(t, CircuitClosed, cb.State())
+	record(cb, false)
+	assert.Equal(t, CircuitOpen, cb.State())
+}
+
+func TestGoverCircuitBreaker(t *testing.T) {
+	cb, _ := NewCircuitBreaker(CircuitBreakerConfig{ConsecutiveFailures: 2, CoolDown: time.Hour})
+
+	tryNum := 0
+	job := func(ctx context.Context) error {
+		tryNum++
+		return fmt.Errorf("try %d", tryNum)
+	}
+
+	//the retries stop once the circuit is open
+	gover, err := New(job, WithCircuitBreaker(cb), WithMaxRetry(5), WithRetryInterval(0))
+	assert.NoError(t, err)
+	err = gover.Run(context.Background())
+	assert.Equal(t, true, errors.Is(err, ErrCircuitOpen))
+	assert.Equal(t, 2, tryNum)
+	var retryErr *RetryError
+	assert.Equal(t, true, errors.As(err, &retryErr))
+	assert.Equal(t, 2, len(retryErr.Attempts))
+
+	//every gover with the same breaker fails fast without running the job
+	other, _ := New(job, WithCircuitBreaker(cb))
+	err = other.Run(context.Background())
+	assert.Equal(t, true, errors.Is(err, ErrCircuitOpen))
+	assert.Equal(t, 2, tryNum)
+}
@@ -0,0 +1,289 @@
+//clock used by gotermin and gover to tell the time and to wait
+//by default it is the system clock, but it can be replaced (e.g. with FakeClock in tests)
+package gover
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+//source of the current time and of the waiting functions
+type Clock interface {
+	//current time
+	Now() time.Time
+	//channel that receives the current time after the duration has passed
+	After(d time.Duration) <-chan time.Time
+	//timer that fires once after the duration has passed
+	NewTimer(d time.Duration) Timer
+	//block until the duration has passed
+	Sleep(d time.Duration)
+}
+
+//timer created by a clock, in principal the same as time.Timer
+type Timer interface {
+	//channel that receives the time when the timer fires
+	C() <-chan time.Time
+	//stop the timer, return false if it has already fired or been stopped
+	Stop() bool
+	//change the timer to fire after the duration, return false if it has already fired or been stopped
+	Reset(d time.Duration) bool
+}
+
+//set the clock used to tell the time and to wait
+//default is the system clock
+func WithClock(clock Clock) Option {
+	return func(c *config) error {
+		if clock == nil {
+			return ClockError
+		}
+		c.clock = clock
+		return nil
+	}
+}
+
+///////////////////////////////
+//////// REAL CLOCK //////////
+/////////////////////////////
+
+//the system clock, simply uses the time library
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+func (realClock) NewTimer(d time.Duration) Timer         { return realTimer{time.NewTimer(d)} }
+func (realClock) Sleep(d time.Duration)                  { time.Sleep(d) }
+
+type realTimer struct {
+	timer *time.Timer
+}
+
+func (rt realTimer) C() <-chan time.Time        { return rt.timer.C }
+func (rt realTimer) Stop() bool                 { return rt.timer.Stop() }
+func (rt realTimer) Reset(d time.Duration) bool { return rt.timer.Reset(d) }
+
+///////////////////////////////
+//////// FAKE CLOCK //////////
+/////////////////////////////
+
+//clock that only moves when it is told to
+//the timers fire as soon as the clock is advanced beyond their time
+//it is safe for concurrent use
+type FakeClock struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	now    time.Time
+	timers []*fakeTimer
+}
+
+//create a fake clock that starts at the given time
+func NewFakeClock(now time.Time) *FakeClock {
+	fc := &FakeClock{now: now}
+	fc.cond = sync.NewCond(&fc.mu)
+	return fc
+}
+
+func (fc *FakeClock) Now() time.Time {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	return fc.now
+}
+
+func (fc *FakeClock) After(d time.Duration) <-chan time.Time {
+	return fc.NewTimer(d).C()
+}
+
+func (fc *FakeClock) NewTimer(d time.Duration) Timer {
+	ft := &fakeTimer{clock: fc, c: make(chan time.Time, 1)}
+	ft.Reset(d)
+	return ft
+}
+
+func (fc *FakeClock) Sleep(d time.Duration) {
+	<-fc.After(d)
+}
+
+//move the clock forward and fire all timers that are due, from the earliest
+func (fc *FakeClock) Advance(d time.Duration) {
+	fc.mu.Lock()
+	fc.setLocked(fc.now.Add(d))
+	fc.mu.Unlock()
+}
+
+//set the clock into the given time, the time can't go backward
+func (fc *FakeClock) Set(t time.Time) {
+	fc.mu.Lock()
+	if t.After(fc.now) {
+		fc.setLocked(t)
+	}
+	fc.mu.Unlock()
+}
+
+func (fc *FakeClock) setLocked(t time.Time) {
+	fc.now = t
+
+	//fire the due timers in the order of their time
+	sort.SliceStable(fc.timers, func(i, j int) bool { return fc.timers[i].when.Before(fc.timers[j].when) })
+	var pending []*fakeTimer
+	for _, ft := range fc.timers {
+		if ft.when.After(t) {
+			pending = append(pending, ft)
+			continue
+		}
+		ft.fire(t)
+	}
+	fc.timers = pending
+	fc.cond.Broadcast()
+}
+
+//number of timers (including sleeps) waiting for the clock to move
+func (fc *FakeClock) Waiters() int {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	return len(fc.timers)
+}
+
+//block until at least n timers are waiting for the clock to move
+//use it before Advance to make sure the scheduler is already waiting
+func (fc *FakeClock) BlockUntil(n int) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	for len(fc.timers) < n {
+		fc.cond.Wait()
+	}
+}
+
+//remove the timer from the waiting list, return false if it's not there
+func (fc *FakeClock) removeLocked(ft *fakeTimer) bool {
+	for i, timer := range fc.timers {
+		if timer == ft {
+			fc.timers = append(fc.timers[:i], fc.timers[i+1:]...)
+			fc.cond.Broadcast()
+			return true
+		}
+	}
+	return false
+}
+
+type fakeTimer struct {
+	clock *FakeClock
+	c     chan time.Time
+	when  time.Time
+}
+
+func (ft *fakeTimer) C() <-chan time.Time { return ft.c }
+
+func (ft *fakeTimer) Stop() bool {
+	ft.clock.mu.Lock()
+	defer ft.clock.mu.Unlock()
+	return ft.clock.removeLocked(ft)
+}
+
+func (ft *fakeTimer) Reset(d time.Duration) bool {
+	fc := ft.clock
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	active := fc.removeLocked(ft)
+	ft.when = fc.now.Add(d)
+
+	//fire immediately if the duration is not positive
+	if d <= 0 {
+		ft.fire(fc.now)
+		return active
+	}
+
+	fc.timers = append(fc.timers, ft)
+	fc.cond.Broadcast()
+	return active
+}
+
+//send the time without blocking, the same as time.Timer
+func (ft *fakeTimer) fire(t time.Time) {
+	select {
+	case ft.c <- t:
+	default:
+	}
+}
+
+///////////////////////////////
+////// CLOCK CONTEXT /////////
+/////////////////////////////
+
+//create a context that expires on the deadline according to the clock
+//the system clock simply uses context.WithDeadline
+func withClockDeadline(parent context.Context, clock Clock, deadline time.Time) (context.Context, context.CancelFunc) {
+	if _, ok := clock.(realClock); ok {
+		return context.WithDeadline(parent, deadline)
+	}
+
+	//don't extend the deadline of the parent
+	if current, ok := parent.Deadline(); ok && current.Before(deadline) {
+		deadline = current
+	}
+
+	ctx := &clockContext{parent: parent, deadline: deadline, done: make(chan struct{})}
+	stop := make(chan struct{})
+	var once sync.Once
+	cancel := func() {
+		ctx.cancel(context.Canceled)
+		once.Do(func() { close(stop) })
+	}
+
+	//the same as the standard library it's already done if the parent is or the deadline has passed
+	if err := parent.Err(); err != nil {
+		ctx.cancel(err)
+	} else if !deadline.After(clock.Now()) {
+		ctx.cancel(context.DeadlineExceeded)
+	}
+
+	//wait for the deadline or the parent in the background
+	timer := clock.NewTimer(deadline.Sub(clock.Now()))
+	go func() {
+		select {
+		case <-timer.C():
+			ctx.cancel(context.DeadlineExceeded)
+		case <-parent.Done():
+			timer.Stop()
+			ctx.cancel(parent.Err())
+		case <-stop:
+			timer.Stop()
+		}
+	}()
+
+	return ctx, cancel
+}
+
+//context with a deadline based on a clock other than the system clock
+//it doesn't embed any cancel context of the standard library, so its children
+//watch its done channel and take over its error, e.g. context.DeadlineExceeded
+type clockContext struct {
+	parent   context.Context
+	deadline time.Time
+	done     chan struct{}
+
+	mu  sync.Mutex
+	err error
+}
+
+func (cc *clockContext) Deadline() (time.Time, bool)       { return cc.deadline, true }
+func (cc *clockContext) Done() <-chan struct{}             { return cc.done }
+func (cc *clockContext) Value(key interface{}) interface{} { return cc.parent.Value(key) }
+
+func (cc *clockContext) Err() error {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	return cc.err
+}
+
+//set the error and close the done channel together
+//the first one wins, e.g. the cancellation before the deadline
+func (cc *clockContext) cancel(err error) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	if cc.err == nil {
+		cc.err = err
+		close(cc.done)
+	}
+}
@@ -0,0 +1,142 @@
+package gover
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2016, 11, 4, 10, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+	assert.Equal(t, start, clock.Now())
+
+	after := clock.After(time.Minute)
+	timer := clock.NewTimer(time.Hour)
+	assert.Equal(t, 2, clock.Waiters())
+
+	//nothing should fire before the clock moves
+	select {
+	case <-after:
+		t.Error("after should not fire yet")
+	case <-timer.C():
+		t.Error("timer should not fire yet")
+	default:
+	}
+
+	clock.Advance(time.Minute)
+	assert.Equal(t, start.Add(time.Minute), <-after)
+	assert.Equal(t, 1, clock.Waiters())
+
+	//stopped timer never fires
+	assert.Equal(t, true, timer.Stop())
+	assert.Equal(t, false, timer.Stop())
+	clock.Advance(time.Hour)
+	select {
+	case <-timer.C():
+		t.Error("stopped timer should not fire")
+	default:
+	}
+
+	//reset makes it wait again
+	assert.Equal(t, false, timer.Reset(time.Second))
+	clock.Set(clock.Now().Add(time.Second))
+	assert.Equal(t, start.Add(time.Hour+time.Minute+time.Second), <-timer.C())
+
+	//non positive duration fires immediately
+	<-clock.After(0)
+
+	//sleep blocks until the clock moves
+	done := make(chan struct{})
+	go func() {
+		clock.Sleep(time.Second * 10)
+		close(done)
+	}()
+	clock.BlockUntil(1)
+	clock.Advance(time.Second * 10)
+	<-done
+
+	//the clock never goes backward
+	now := clock.Now()
+	clock.Set(start)
+	assert.Equal(t, now, clock.Now())
+}
+
+func TestWithClockDeadline(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+
+	ctx, cancel := withClockDeadline(context.Background(), clock, clock.Now().Add(time.Minute))
+	defer cancel()
+	deadline, ok := ctx.Deadline()
+	assert.Equal(t, true, ok)
+	assert.Equal(t, clock.Now().Add(time.Minute), deadline)
+	assert.NoError(t, ctx.Err())
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	<-ctx.Done()
+	assert.Equal(t, context.DeadlineExceeded, ctx.Err())
+
+	//cancel before the deadline
+	ctx, cancel = withClockDeadline(context.Background(), clock, clock.Now().Add(time.Minute))
+	cancel()
+	<-ctx.Done()
+	assert.Equal(t, context.Canceled, ctx.Err())
+
+	//the deadline of the parent is kept if it's earlier
+	parent, parentCancel := withClockDeadline(context.Background(), clock, clock.Now().Add(time.Second))
+	defer parentCancel()
+	ctx, cancel = withClockDeadline(parent, clock, clock.Now().Add(time.Hour))
+	defer cancel()
+	deadline, _ = ctx.Deadline()
+	assert.Equal(t, clock.Now().Add(time.Second), deadline)
+
+	//system clock uses the standard library
+	ctx, cancel = withClockDeadline(context.Background(), realClock{}, time.Now().Add(time.Millisecond))
+	defer cancel()
+	<-ctx.Done()
+	assert.Equal(t, context.DeadlineExceeded, ctx.Err())
+}
+
+func TestClockContextChild(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+
+	//the children take over the deadline error
+	ctx, cancel := withClockDeadline(context.Background(), clock, clock.Now().Add(time.Minute))
+	defer cancel()
+	child, childCancel := context.WithCancel(ctx)
+	defer childCancel()
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	<-child.Done()
+	assert.Equal(t, context.DeadlineExceeded, child.Err())
+	assert.Equal(t, context.DeadlineExceeded, ctx.Err())
+
+	//the error is already set once it's done
+	ctx, cancel = withClockDeadline(context.Background(), clock, clock.Now().Add(time.Minute))
+	clock.BlockUntil(1)
+	go clock.Advance(time.Minute)
+	<-ctx.Done()
+	assert.Equal(t, context.DeadlineExceeded, ctx.Err())
+	cancel()
+	assert.Equal(t, context.DeadlineExceeded, ctx.Err())
+
+	//the cancellation of the parent and the passed deadline
+	parent, parentCancel := context.WithCancel(context.Background())
+	ctx, cancel = withClockDeadline(parent, clock, clock.Now().Add(time.Minute))
+	defer cancel()
+	parentCancel()
+	<-ctx.Done()
+	assert.Equal(t, context.Canceled, ctx.Err())
+
+	ctx, cancel = withClockDeadline(context.Background(), clock, clock.Now())
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, ctx.Err())
+
+	//the values of the parent are still there
+	type key struct{}
+	ctx, cancel = withClockDeadline(context.WithValue(context.Background(), key{}, "value"), clock, clock.Now().Add(time.Minute))
+	defer cancel()
+	assert.Equal(t, "value", ctx.Value(key{}))
+}
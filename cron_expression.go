@@ -0,0 +1,262 @@
+//cron expression interval to be used by GoTermin
+//it supports the standard five fields: minute hour day-of-month month day-of-week
+//each field can be a wildcard "*", a value, a range "a-b", a list "a,b,c"
+//and a step "*/n", "a-b/n" or "a/n"
+//month and weekday fields also accept names (e.g. "JAN" or "MON")
+//the macros @yearly, @annually, @monthly, @weekly, @daily, @midnight and @hourly are also accepted
+package gover
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//the macros and their equivalent expressions
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+//the boundaries of a cron field, including the names allowed in it
+type cronField struct {
+	name  string
+	min   int
+	max   int
+	names map[string]int
+}
+
+var (
+	minuteField = cronField{name: "minute", min: 0, max: 59}
+	hourField   = cronField{name: "hour", min: 0, max: 23}
+	domField    = cronField{name: "day of month", min: 1, max: 31}
+	monthField  = cronField{name: "month", min: 1, max: 12, names: map[string]int{
+		"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6,
+		"JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12,
+	}}
+	//both 0 and 7 are sunday
+	dowField = cronField{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"SUN": 0, "MON": 1, "TUE": 2, "WED": 3, "THU": 4, "FRI": 5, "SAT": 6,
+	}}
+)
+
+//goTermin that runs based on a cron expression
+//every field is saved as a bitset where bit n is set if value n matches
+type cronJob struct {
+	expression   string
+	minute       uint64
+	hour         uint64
+	dom          uint64
+	month        uint64
+	dow          uint64
+	timeLocation *time.Location
+	dstPolicy    DSTPolicy
+	//standard cron runs the job if either day of month or day of week matches
+	//unless one of them is a wildcard, then only the other one counts
+	domStar bool
+	dowStar bool
+}
+
+//parse a cron expression into a cronJob
+//return CronExpressionError if the expression is not valid
+func parseCronExpression(expression string, loc *time.Location, policy DSTPolicy) (cronJob, error) {
+	result := cronJob{expression: expression, timeLocation: loc, dstPolicy: policy}
+
+	spec := strings.TrimSpace(expression)
+	if strings.HasPrefix(spec, "@") {
+		macro, ok := cronMacros[strings.ToLower(spec)]
+		if !ok {
+			return result, fmt.Errorf("%w: unknown macro %s", CronExpressionError, spec)
+		}
+		spec = macro
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return result, fmt.Errorf("%w: expected 5 fields but got %d", CronExpressionError, len(fields))
+	}
+
+	var err error
+	if result.minute, err = minuteField.parse(fields[0]); err != nil {
+		return result, err
+	}
+	if result.hour, err = hourField.parse(fields[1]); err != nil {
+		return result, err
+	}
+	if result.dom, err = domField.parse(fields[2]); err != nil {
+		return result, err
+	}
+	if result.month, err = monthField.parse(fields[3]); err != nil {
+		return result, err
+	}
+	if result.dow, err = dowField.parse(fields[4]); err != nil {
+		return result, err
+	}
+
+	//fold sunday 7 into sunday 0
+	if result.dow&(1<<7) != 0 {
+		result.dow = (result.dow | 1) &^ (1 << 7)
+	}
+
+	result.domStar = strings.HasPrefix(fields[2], "*")
+	result.dowStar = strings.HasPrefix(fields[4], "*")
+
+	return result, nil
+}
+
+//parse a single field, which can be a comma separated list
+func (cf cronField) parse(field string) (uint64, error) {
+	var result uint64
+	for _, part := range strings.Split(field, ",") {
+		bits, err := cf.parsePart(part)
+		if err != nil {
+			return 0, err
+		}
+		result |= bits
+	}
+	return result, nil
+}
+
+//parse one element of a list, e.g. "*", "5", "1-5", "*/15" or "MON-FRI/2"
+func (cf cronField) parsePart(part string) (uint64, error) {
+	rangePart, step := part, 1
+
+	if i := strings.Index(part, "/"); i >= 0 {
+		rangePart = part[:i]
+		s, err := strconv.Atoi(part[i+1:])
+		if err != nil || s < 1 {
+			return 0, fmt.Errorf("%w: invalid step %q in %s field", CronExpressionError, part, cf.name)
+		}
+		step = s
+	}
+
+	var start, end int
+	switch {
+	case rangePart == "*":
+		start, end = cf.min, cf.max
+	case strings.Contains(rangePart, "-"):
+		bounds := strings.SplitN(rangePart, "-", 2)
+		var err error
+		if start, err = cf.value(bounds[0]); err != nil {
+			return 0, err
+		}
+		if end, err = cf.value(bounds[1]); err != nil {
+			return 0, err
+		}
+	default:
+		var err error
+		if start, err = cf.value(rangePart); err != nil {
+			return 0, err
+		}
+		end = start
+		//"a/n" means starting from a until the maximum value
+		if strings.Contains(part, "/") {
+			end = cf.max
+		}
+	}
+
+	if start > end {
+		return 0, fmt.Errorf("%w: invalid range %q in %s field", CronExpressionError, part, cf.name)
+	}
+
+	var result uint64
+	for i := start; i <= end; i += step {
+		result |= 1 << uint(i)
+	}
+	return result, nil
+}
+
+//convert a single value (either number or name) and check its boundaries
+func (cf cronField) value(s string) (int, error) {
+	if v, ok := cf.names[strings.ToUpper(s)]; ok {
+		return v, nil
+	}
+
+	v, err := strconv.Atoi(s)
+	if err != nil || v < cf.min || v > cf.max {
+		return 0, fmt.Errorf("%w: invalid value %q in %s field", CronExpressionError, s, cf.name)
+	}
+	return v, nil
+}
+
+//check whether the given day matches both the month and the day fields
+func (cj cronJob) matchDay(t time.Time) bool {
+	if cj.month&(1<<uint(t.Month())) == 0 {
+		return false
+	}
+
+	domMatch := cj.dom&(1<<uint(t.Day())) != 0
+	dowMatch := cj.dow&(1<<uint(t.Weekday())) != 0
+
+	if cj.domStar || cj.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+//calculate the next time the expression matches, strictly after the input time
+//nonexistent and ambiguous times are handled based on the DST policy
+//the search is limited to 5 years, after that the zero time is returned
+func (cj cronJob) Next(after time.Time) time.Time {
+	after = after.In(cj.timeLocation)
+	day := time.Date(after.Year(), after.Month(), after.Day(), 0, 0, 0, 0, cj.timeLocation)
+
+	for i := 0; i < 5*366; i++ {
+		current := day.AddDate(0, 0, i)
+		if !cj.matchDay(current) {
+			continue
+		}
+
+		//on the day of DST transition the wall clock is not in order anymore
+		//so every match has to be resolved and the earliest one is taken
+		_, offsetStart := current.Zone()
+		_, offsetEnd := current.AddDate(0, 0, 1).Zone()
+		transition := offsetStart != offsetEnd
+
+		var result time.Time
+		for hour := 0; hour < 24; hour++ {
+			if cj.hour&(1<<uint(hour)) == 0 {
+				continue
+			}
+			for minute := 0; minute < 60; minute++ {
+				if cj.minute&(1<<uint(minute)) == 0 {
+					continue
+				}
+
+				if !transition {
+					candidate := time.Date(current.Year(), current.Month(), current.Day(), hour, minute, 0, 0, cj.timeLocation)
+					if candidate.After(after) {
+						return candidate
+					}
+					continue
+				}
+
+				candidate := cj.dstPolicy.firstAfter(after, current.Year(), current.Month(), current.Day(), hour, minute, 0, 0, cj.timeLocation)
+				if !candidate.IsZero() && (result.IsZero() || candidate.Before(result)) {
+					result = candidate
+				}
+			}
+		}
+
+		if !result.IsZero() {
+			return result
+		}
+	}
+
+	return time.Time{}
+}
+
+//cron has a resolution of one minute
+//the real interval between runs is determined by Next
+func (cj cronJob) getInterval() time.Duration { return time.Minute }
+func (cj cronJob) runsImmediately() bool      { return false }
+
+func (cj cronJob) String() string {
+	return fmt.Sprintf("[cron] %s", cj.expression)
+}
@@ -0,0 +1,83 @@
+package gover
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestParseCronExpression(t *testing.T) {
+	invalids := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"* * * FOO *",
+		"@fortnightly",
+	}
+	for _, expr := range invalids {
+		_, err := parseCronExpression(expr, globalTimeLoc, DSTPolicy{})
+		assert.Error(t, err, expr)
+	}
+
+	cj, err := parseCronExpression("*/15 9-17 * * MON-FRI", globalTimeLoc, DSTPolicy{})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1<<0|1<<15|1<<30|1<<45), cj.minute)
+	assert.Equal(t, uint64(1<<1|1<<2|1<<3|1<<4|1<<5), cj.dow)
+	assert.Equal(t, true, cj.domStar)
+	assert.Equal(t, false, cj.dowStar)
+
+	cj, err = parseCronExpression("0 0 * * 7", globalTimeLoc, DSTPolicy{})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), cj.dow)
+
+	cj, err = parseCronExpression("10/20 1,3-5 * jan-mar/2 *", globalTimeLoc, DSTPolicy{})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1<<10|1<<30|1<<50), cj.minute)
+	assert.Equal(t, uint64(1<<1|1<<3|1<<4|1<<5), cj.hour)
+	assert.Equal(t, uint64(1<<1|1<<3), cj.month)
+
+	cj, err = parseCronExpression("@Daily", globalTimeLoc, DSTPolicy{})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), cj.minute)
+	assert.Equal(t, uint64(1), cj.hour)
+}
+
+func TestCronNextRun(t *testing.T) {
+	parse := func(s string) time.Time {
+		result, _ := time.ParseInLocation("2006-01-02 15:04", s, globalTimeLoc)
+		return result
+	}
+
+	cj, _ := parseCronExpression("*/15 9-17 * * MON-FRI", globalTimeLoc, DSTPolicy{})
+	//friday afternoon
+	assert.Equal(t, parse("2016-11-04 17:45"), cj.Next(parse("2016-11-04 17:31")))
+	//after the last run on friday, it's monday morning
+	assert.Equal(t, parse("2016-11-07 09:00"), cj.Next(parse("2016-11-04 17:45")))
+
+	//either day of month or day of week should match
+	cj, _ = parseCronExpression("0 12 13 * FRI", globalTimeLoc, DSTPolicy{})
+	assert.Equal(t, parse("2016-11-11 12:00"), cj.Next(parse("2016-11-04 12:00")))
+	assert.Equal(t, parse("2016-11-13 12:00"), cj.Next(parse("2016-11-11 12:00")))
+
+	cj, _ = parseCronExpression("@yearly", globalTimeLoc, DSTPolicy{})
+	assert.Equal(t, parse("2017-01-01 00:00"), cj.Next(parse("2016-01-01 00:00")))
+
+	cj, _ = parseCronExpression("0 0 29 2 *", globalTimeLoc, DSTPolicy{})
+	assert.Equal(t, parse("2020-02-29 00:00"), cj.Next(parse("2016-03-01 00:00")))
+
+	//the input time is converted into the cron location first
+	utc := parse("2016-11-04 17:31").UTC()
+	cj, _ = parseCronExpression("*/15 9-17 * * MON-FRI", globalTimeLoc, DSTPolicy{})
+	assert.Equal(t, parse("2016-11-04 17:45").Unix(), cj.Next(utc).Unix())
+
+	//impossible date will never run
+	cj, _ = parseCronExpression("0 0 31 2 *", globalTimeLoc, DSTPolicy{})
+	assert.Equal(t, true, cj.Next(parse("2016-01-01 00:00")).IsZero())
+}
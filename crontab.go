@@ -3,109 +3,163 @@ package gover
 import (
 	"context"
 	"fmt"
+	"sort"
+	"sync"
 	"time"
 )
 
 //containers for all gotermins
 //can be used as main controller or to retain informations
+//it is safe to be used from multiple goroutines
 type CrontabMinE struct {
+	//protect the map of gotermins
+	mu sync.RWMutex
 	//list of registered gotermins in form of a map
 	//the map key is the register name
 	cronjobs map[string]*Gotermin
 	//the set timezone
 	//all gotermins will run in this timezone
 	timeLocation *time.Location
+	//default options for all gotermins
+	//options given on registration are applied after these
+	options []Option
 }
 
 //create new container with a certain time location
-//return error if time location is empty
-func NewCrontab(loc *time.Location) (*CrontabMinE, error) {
+//the options will be used as default for all registered gotermins
+//return error if time location is empty or any option is not valid
+func NewCrontab(loc *time.Location, opts ...Option) (*CrontabMinE, error) {
 	if loc == nil {
 		return nil, TimeLocationError
 	}
 
+	if _, err := newConfig(targetCrontab, opts); err != nil {
+		return nil, err
+	}
+
 	return &CrontabMinE{
 		cronjobs:     map[string]*Gotermin{},
 		timeLocation: loc,
+		options:      opts,
 	}, nil
 }
 
-//register gotermins on the crontab with key
-//the requirement is exactly the same for each category
-//only this time use location from crontab
-//return error if failed to create the gotermin
-func (ct *CrontabMinE) RegisterNewHourly(key string, job func(context.Context), minute string) error {
+//combine the default options of the crontab with the ones given on registration
+func (ct *CrontabMinE) withOptions(opts []Option) []Option {
+	result := make([]Option, 0, len(ct.options)+len(opts))
+	result = append(result, ct.options...)
+	return append(result, opts...)
+}
+
+//add the gotermin into crontab with the key
+//the gotermin is only created if the key is not registered yet
+func (ct *CrontabMinE) register(key string, create func() (*Gotermin, error)) error {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+
 	//return error if duplicate key is found
 	if _, ok := ct.cronjobs[key]; ok {
 		return DuplicateKeyError
 	}
 
-	if gotermin, err := NewHourly(job, minute, ct.timeLocation); err != nil {
+	gotermin, err := create()
+	if err != nil {
 		return err
-	} else {
-		//if there's no error then add the key into crontab
-		ct.cronjobs[key] = gotermin
 	}
 
+	//if there's no error then add the key into crontab
+	gotermin.key = key
+	ct.cronjobs[key] = gotermin
 	return nil
 }
 
-func (ct *CrontabMinE) RegisterNewDaily(key string, job func(context.Context), hour string) error {
-	//return error if duplicate key is found
-	if _, ok := ct.cronjobs[key]; ok {
-		return DuplicateKeyError
-	}
+//register gotermins on the crontab with key
+//the requirement is exactly the same for each category
+//only this time use location and default options from crontab
+//return error if failed to create the gotermin
+func (ct *CrontabMinE) RegisterNewHourly(key string, job func(context.Context), minute string, opts ...Option) error {
+	return ct.register(key, func() (*Gotermin, error) {
+		return NewHourly(job, minute, ct.timeLocation, ct.withOptions(opts)...)
+	})
+}
 
-	if gotermin, err := NewDaily(job, hour, ct.timeLocation); err != nil {
-		return err
-	} else {
-		//if there's no error then add the key into crontab
-		ct.cronjobs[key] = gotermin
-	}
+//every category has a variant where the job returns an error
+//the last error and the last success can be seen in the stats of the gotermin
+func (ct *CrontabMinE) RegisterNewHourlyWithError(key string, job func(context.Context) error, minute string, opts ...Option) error {
+	return ct.register(key, func() (*Gotermin, error) {
+		return NewHourlyWithError(job, minute, ct.timeLocation, ct.withOptions(opts)...)
+	})
+}
 
-	return nil
+func (ct *CrontabMinE) RegisterNewDaily(key string, job func(context.Context), hour string, opts ...Option) error {
+	return ct.register(key, func() (*Gotermin, error) {
+		return NewDaily(job, hour, ct.timeLocation, ct.withOptions(opts)...)
+	})
 }
 
-func (ct *CrontabMinE) RegisterNewWeekly(key string, job func(context.Context), weekly string) error {
-	//return error if duplicate key is found
-	if _, ok := ct.cronjobs[key]; ok {
-		return DuplicateKeyError
-	}
+func (ct *CrontabMinE) RegisterNewDailyWithError(key string, job func(context.Context) error, hour string, opts ...Option) error {
+	return ct.register(key, func() (*Gotermin, error) {
+		return NewDailyWithError(job, hour, ct.timeLocation, ct.withOptions(opts)...)
+	})
+}
 
-	if gotermin, err := NewWeekly(job, weekly, ct.timeLocation); err != nil {
-		return err
-	} else {
-		//if there's no error then add the key into crontab
-		ct.cronjobs[key] = gotermin
-	}
+func (ct *CrontabMinE) RegisterNewWeekly(key string, job func(context.Context), weekly string, opts ...Option) error {
+	return ct.register(key, func() (*Gotermin, error) {
+		return NewWeekly(job, weekly, ct.timeLocation, ct.withOptions(opts)...)
+	})
+}
 
-	return nil
+func (ct *CrontabMinE) RegisterNewWeeklyWithError(key string, job func(context.Context) error, weekly string, opts ...Option) error {
+	return ct.register(key, func() (*Gotermin, error) {
+		return NewWeeklyWithError(job, weekly, ct.timeLocation, ct.withOptions(opts)...)
+	})
 }
 
-func (ct *CrontabMinE) RegisterNewCustomInterval(key string, job func(context.Context), customInterval time.Duration) error {
-	//return error if duplicate key is found
-	if _, ok := ct.cronjobs[key]; ok {
-		return DuplicateKeyError
-	}
+func (ct *CrontabMinE) RegisterNewMonthly(key string, job func(context.Context), monthly string, opts ...Option) error {
+	return ct.register(key, func() (*Gotermin, error) {
+		return NewMonthly(job, monthly, ct.timeLocation, ct.withOptions(opts)...)
+	})
+}
 
-	if gotermin, err := NewCustomInterval(job, customInterval, ct.timeLocation); err != nil {
-		return err
-	} else {
-		//if there's no error then add the key into crontab
-		ct.cronjobs[key] = gotermin
-	}
+func (ct *CrontabMinE) RegisterNewMonthlyWithError(key string, job func(context.Context) error, monthly string, opts ...Option) error {
+	return ct.register(key, func() (*Gotermin, error) {
+		return NewMonthlyWithError(job, monthly, ct.timeLocation, ct.withOptions(opts)...)
+	})
+}
 
-	return nil
+func (ct *CrontabMinE) RegisterNewCustomInterval(key string, job func(context.Context), customInterval time.Duration, opts ...Option) error {
+	return ct.register(key, func() (*Gotermin, error) {
+		return NewCustomInterval(job, customInterval, ct.timeLocation, ct.withOptions(opts)...)
+	})
+}
+
+func (ct *CrontabMinE) RegisterNewCustomIntervalWithError(key string, job func(context.Context) error, customInterval time.Duration, opts ...Option) error {
+	return ct.register(key, func() (*Gotermin, error) {
+		return NewCustomIntervalWithError(job, customInterval, ct.timeLocation, ct.withOptions(opts)...)
+	})
+}
+
+func (ct *CrontabMinE) RegisterNewCron(key string, job func(context.Context), expression string, opts ...Option) error {
+	return ct.register(key, func() (*Gotermin, error) {
+		return NewCron(job, expression, ct.timeLocation, ct.withOptions(opts)...)
+	})
+}
+
+func (ct *CrontabMinE) RegisterNewCronWithError(key string, job func(context.Context) error, expression string, opts ...Option) error {
+	return ct.register(key, func() (*Gotermin, error) {
+		return NewCronWithError(job, expression, ct.timeLocation, ct.withOptions(opts)...)
+	})
 }
 
 //start all inactive gotermins
 //return error if any of them is failing
 func (ct *CrontabMinE) StartAll() error {
+	ct.mu.RLock()
+	defer ct.mu.RUnlock()
+
 	for _, gotermin := range ct.cronjobs {
-		if !gotermin.isActive {
-			if err := gotermin.Start(); err != nil {
-				return err
-			}
+		if err := gotermin.Start(); err != nil && err != SchedulerActiveError {
+			return err
 		}
 	}
 	return nil
@@ -114,77 +168,124 @@ func (ct *CrontabMinE) StartAll() error {
 //start a certain gotermin
 //return error if key is not found or failed to start
 func (ct *CrontabMinE) Start(key string) error {
-	gotermin, found := ct.cronjobs[key]
-	if !found {
-		return KeyNotFoundError
+	gotermin, err := ct.GetCronjob(key)
+	if err != nil {
+		return err
 	}
 
 	return gotermin.Start()
 }
 
 //stop all active gotermins
-//return error if any of them is failing
 func (ct *CrontabMinE) StopAll() {
+	ct.mu.RLock()
+	defer ct.mu.RUnlock()
+
 	for _, gotermin := range ct.cronjobs {
-		go gotermin.Stop()
+		gotermin.Stop()
+	}
+}
+
+//stop all gotermins and cancel their running jobs
+//then wait until all jobs have returned or the context is done
+//return ShutdownError with the keys whose jobs are not finished in time
+func (ct *CrontabMinE) Shutdown(ctx context.Context) error {
+	ct.mu.RLock()
+	cronjobs := make(map[string]*Gotermin, len(ct.cronjobs))
+	for key, gotermin := range ct.cronjobs {
+		cronjobs[key] = gotermin
+	}
+	ct.mu.RUnlock()
+
+	//shutdown all of them at the same time
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var unfinished []string
+	for key, gotermin := range cronjobs {
+		wg.Add(1)
+		go func(key string, gotermin *Gotermin) {
+			defer wg.Done()
+			if err := gotermin.Shutdown(ctx); err != nil {
+				mu.Lock()
+				unfinished = append(unfinished, key)
+				mu.Unlock()
+			}
+		}(key, gotermin)
+	}
+	wg.Wait()
+
+	if len(unfinished) > 0 {
+		sort.Strings(unfinished)
+		return &ShutdownError{Keys: unfinished, Err: ctx.Err()}
 	}
+	return nil
 }
 
 //stop a certain gotermin
 //return error if key is not found or failed to stop
 func (ct *CrontabMinE) Stop(key string) error {
-	gotermin, found := ct.cronjobs[key]
-	if !found {
-		return KeyNotFoundError
+	gotermin, err := ct.GetCronjob(key)
+	if err != nil {
+		return err
 	}
 
 	return gotermin.Stop()
 }
 
 //return the summary of current crontab
-func (ct CrontabMinE) String() string {
+func (ct *CrontabMinE) String() string {
+	ct.mu.RLock()
+	defer ct.mu.RUnlock()
+
 	result := fmt.Sprintf(`
 Summary
-Key-----[Interval] StartingPoint-----Status`)
+Key-----[Interval] StartingPoint-----Status-----LastSuccess-----LastError`)
 
 	for key, cronjob := range ct.cronjobs {
 		isActive := "inactive"
-		if cronjob.isActive {
+		if cronjob.IsActive() {
 			isActive = "active"
 		}
 
+		stats := cronjob.Stats()
+		lastSuccess, lastError := "never", "none"
+		if !stats.LastSuccess.IsZero() {
+			lastSuccess = stats.LastSuccess.In(ct.timeLocation).Format(time.RFC3339)
+		}
+		if stats.LastError != nil {
+			lastError = stats.LastError.Error()
+		}
+
 		result += fmt.Sprintf(`
-%s-----%s-----%s`, key, cronjob.jobInterval, isActive)
+%s-----%s-----%s-----%s-----%s`, key, cronjob.jobInterval, isActive, lastSuccess, lastError)
 	}
 
 	return result
 }
 
-//get all active keys from a crontab struct
-func (ct CrontabMinE) GetAllKeys() []string {
-	var result []string
-	for key, _ := range ct.cronjobs {
-		result = append(result, key)
-	}
-	return result
+//get all keys from a crontab struct
+func (ct *CrontabMinE) GetAllKeys() []string {
+	return ct.filterKeys(func(*Gotermin) bool { return true })
 }
 
 //get only active keys from a crontab struct
-func (ct CrontabMinE) GetActiveKeys() []string {
-	var result []string
-	for key, val := range ct.cronjobs {
-		if val.isActive {
-			result = append(result, key)
-		}
-	}
-	return result
+func (ct *CrontabMinE) GetActiveKeys() []string {
+	return ct.filterKeys((*Gotermin).IsActive)
 }
 
 //get only inactive keys from a crontab struct
-func (ct CrontabMinE) GetInactiveKeys() []string {
+func (ct *CrontabMinE) GetInactiveKeys() []string {
+	return ct.filterKeys(func(gt *Gotermin) bool { return !gt.IsActive() })
+}
+
+//get the keys whose gotermin fulfills the condition
+func (ct *CrontabMinE) filterKeys(condition func(*Gotermin) bool) []string {
+	ct.mu.RLock()
+	defer ct.mu.RUnlock()
+
 	var result []string
 	for key, val := range ct.cronjobs {
-		if !val.isActive {
+		if condition(val) {
 			result = append(result, key)
 		}
 	}
@@ -192,8 +293,12 @@ func (ct CrontabMinE) GetInactiveKeys() []string {
 }
 
 //get a GoTermin by a key
+//its stats tell the last error and the last success of the job
 //return error if not found
-func (ct CrontabMinE) GetCronjob(key string) (*Gotermin, error) {
+func (ct *CrontabMinE) GetCronjob(key string) (*Gotermin, error) {
+	ct.mu.RLock()
+	defer ct.mu.RUnlock()
+
 	if gt, ok := ct.cronjobs[key]; ok {
 		return gt, nil
 	}
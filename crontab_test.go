@@ -2,8 +2,11 @@ package gover
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/stretchr/testify/assert"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -130,7 +133,8 @@ func TestRegisterAndStartNew(t *testing.T) {
 	err = crontab.Start("moritz_age")
 	assert.NoError(t, err)
 
-	time.Sleep(time.Second * 3)
+	//stop between the runs, since they happen exactly on the interval
+	time.Sleep(time.Millisecond * 2500)
 
 	err = crontab.Stop("addie_age")
 	assert.NoError(t, err)
@@ -140,6 +144,11 @@ func TestRegisterAndStartNew(t *testing.T) {
 	err = crontab.Stop("moritz_age")
 	assert.NoError(t, err)
 
+	//wait for the last runs before reading the results
+	for _, key := range []string{"addie_age", "moritz_age"} {
+		cronjob, _ := crontab.GetCronjob(key)
+		assert.NoError(t, cronjob.Shutdown(context.Background()))
+	}
 	assert.Equal(t, int64(6), addie.Age)
 	assert.Equal(t, int64(4), moritz.Age)
 
@@ -170,3 +179,124 @@ func TestRegisterAndStartNew(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, true, gt.isActive)
 }
+
+func TestRegisterNewCron(t *testing.T) {
+	jkt, _ := time.LoadLocation("Asia/Jakarta")
+	crontab, _ := NewCrontab(jkt)
+
+	err := crontab.RegisterNewCron("foo", randomFunc, "61 * * * *")
+	assert.Error(t, err)
+
+	err = crontab.RegisterNewCron("foo", randomFunc, "@hourly")
+	assert.NoError(t, err)
+
+	err = crontab.RegisterNewCron("foo", randomFunc, "@daily")
+	assert.Equal(t, DuplicateKeyError, err)
+}
+
+func TestRegisterNewMonthly(t *testing.T) {
+	jkt, _ := time.LoadLocation("Asia/Jakarta")
+	crontab, _ := NewCrontab(jkt)
+
+	err := crontab.RegisterNewMonthly("foo", randomFunc, "32@0200")
+	assert.Error(t, err)
+
+	err = crontab.RegisterNewMonthly("foo", randomFunc, "L@2300")
+	assert.NoError(t, err)
+
+	err = crontab.RegisterNewMonthly("foo", randomFunc, "1@0200")
+	assert.Equal(t, DuplicateKeyError, err)
+}
+
+func TestCrontabConcurrentUse(t *testing.T) {
+	jkt, _ := time.LoadLocation("Asia/Jakarta")
+	clock := NewFakeClock(time.Now())
+	crontab, _ := NewCrontab(jkt, WithClock(clock))
+	job := func(ctx context.Context) {}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			key := fmt.Sprintf("cat%d", i)
+			assert.NoError(t, crontab.RegisterNewCustomInterval(key, job, time.Second))
+			assert.Equal(t, DuplicateKeyError, crontab.RegisterNewHourly(key, job, "30"))
+
+			//others might start or stop it in the meantime
+			if err := crontab.Start(key); err != nil {
+				assert.Equal(t, SchedulerActiveError, err)
+			}
+			crontab.GetActiveKeys()
+			crontab.GetInactiveKeys()
+			_ = crontab.String()
+			if i%5 == 0 {
+				assert.NoError(t, crontab.StartAll())
+			}
+			clock.Advance(time.Second)
+			if err := crontab.Stop(key); err != nil {
+				assert.Equal(t, SchedulerInactiveError, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	crontab.StopAll()
+	assert.Equal(t, 20, len(crontab.GetAllKeys()))
+	assert.Equal(t, 20, len(crontab.GetInactiveKeys()))
+	assert.Equal(t, 0, len(crontab.GetActiveKeys()))
+}
+
+func TestCrontabShutdown(t *testing.T) {
+	jkt, _ := time.LoadLocation("Asia/Jakarta")
+	clock := NewFakeClock(time.Now())
+	crontab, _ := NewCrontab(jkt, WithClock(clock))
+
+	release := make(chan struct{})
+	assert.NoError(t, crontab.RegisterNewCustomInterval("polite", func(ctx context.Context) { <-ctx.Done() }, time.Second))
+	assert.NoError(t, crontab.RegisterNewCustomInterval("stubborn", func(ctx context.Context) { <-release }, time.Second))
+	assert.NoError(t, crontab.RegisterNewHourly("idle", func(ctx context.Context) {}, "30"))
+	assert.NoError(t, crontab.StartAll())
+
+	for _, key := range []string{"polite", "stubborn"} {
+		gt, _ := crontab.GetCronjob(key)
+		eventually(t, func() bool { return gt.Stats().Runs == 1 })
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	err := crontab.Shutdown(ctx)
+	assert.Error(t, err)
+	assert.Equal(t, true, errors.Is(err, context.DeadlineExceeded))
+
+	var shutdownErr *ShutdownError
+	assert.Equal(t, true, errors.As(err, &shutdownErr))
+	assert.Equal(t, []string{"stubborn"}, shutdownErr.Keys)
+	assert.Equal(t, 0, len(crontab.GetActiveKeys()))
+
+	close(release)
+	assert.NoError(t, crontab.Shutdown(context.Background()))
+}
+
+func TestRegisterNewWithError(t *testing.T) {
+	clock := NewFakeClock(time.Date(2016, 11, 4, 10, 20, 0, 0, globalTimeLoc))
+	crontab, _ := NewCrontab(globalTimeLoc, WithClock(clock))
+
+	assert.NoError(t, crontab.RegisterNewCustomIntervalWithError("picky", func(ctx context.Context) error {
+		return errors.New("wrong food")
+	}, time.Minute))
+	assert.NoError(t, crontab.RegisterNewDailyWithError("lazy", func(ctx context.Context) error { return nil }, "1100"))
+	assert.Error(t, crontab.RegisterNewCronWithError("picky", func(ctx context.Context) error { return nil }, "@daily"))
+	assert.NoError(t, crontab.StartAll())
+
+	picky, err := crontab.GetCronjob("picky")
+	assert.NoError(t, err)
+	eventually(t, func() bool { return picky.Stats().Failures == 1 })
+	assert.Equal(t, "wrong food", picky.Stats().LastError.Error())
+
+	summary := crontab.String()
+	assert.Equal(t, true, strings.Contains(summary, "picky-----[1m0s] immediately-----active-----never-----wrong food"))
+	assert.Equal(t, true, strings.Contains(summary, "lazy-----[24h0m0s] 1100-----active-----never-----none"))
+
+	assert.NoError(t, crontab.Shutdown(context.Background()))
+}
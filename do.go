@@ -0,0 +1,57 @@
+//generic retry function that returns the result of the job
+//it uses the same retry and timeout engine as gover, so the job doesn't have to smuggle its result out via closure
+package gover
+
+import (
+	"context"
+	"sync"
+)
+
+//run the job until it's successful and return its result
+//it accepts the same options as New, without the retry options the job runs only once
+//the context can cancel the job and decide the deadline as well as WithTimeout
+//return the zero value and RetryError if the job is not successful
+func Do[T any](ctx context.Context, job func(context.Context) (T, error), opts ...Option) (T, error) {
+	var zero T
+
+	cfg, err := newConfig(targetGover, opts)
+	if err != nil {
+		return zero, err
+	}
+
+	//every successful attempt records its value, the hedged ones run at the same time
+	//and the cancelled ones might still return after the run, so the values are kept by the attempt number and protected
+	var mu sync.Mutex
+	results := map[int]T{}
+	g := newGover(func(ctx context.Context) error {
+		value, err := job(ctx)
+		if err != nil {
+			return err
+		}
+		attempt, _ := AttemptFromContext(ctx)
+		mu.Lock()
+		results[attempt.Number] = value
+		mu.Unlock()
+		return nil
+	}, cfg)
+
+	//only the value of the winning attempt is returned, e.g. not the one of a hedged attempt that's finished later
+	//the hook is called before the run returns
+	var winner int
+	onSuccess := g.Hooks.OnSuccess
+	g.Hooks.OnSuccess = func(attempt Attempt) {
+		winner = attempt.Number
+		if onSuccess != nil {
+			onSuccess(attempt)
+		}
+	}
+
+	//the deadline of the context is kept, unless the timeout is shorter
+	if err := g.Run(ctx); err != nil {
+		return zero, err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	return results[winner], nil
+}
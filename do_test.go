@@ -0,0 +1,118 @@
+package gover
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestDo(t *testing.T) {
+	tryNum := 0
+	meowing := func(ctx context.Context) (string, error) {
+		tryNum++
+		if tryNum < 3 {
+			return "", fmt.Errorf("try %d", tryNum)
+		}
+		return "Meow", nil
+	}
+
+	_, err := Do(context.Background(), meowing, WithClock(nil))
+	assert.Error(t, err)
+	assert.Equal(t, 0, tryNum)
+
+	//without retry the job runs only once
+	result, err := Do(context.Background(), meowing)
+	assert.Equal(t, "", result)
+	assert.Equal(t, true, errors.Is(err, ErrMaxRetry))
+	assert.Equal(t, 1, tryNum)
+
+	tryNum = 0
+	result, err = Do(context.Background(), meowing, WithRetry(RetryConfig{MaxRetry: 3, Backoff: ConstantBackoff(0)}))
+	assert.NoError(t, err)
+	assert.Equal(t, "Meow", result)
+	assert.Equal(t, 3, tryNum)
+
+	//any type can be returned
+	cat, err := Do(context.Background(), func(ctx context.Context) (*Cat, error) {
+		return &Cat{Name: "Moritz", Race: "Persian"}, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "Moritz", cat.Name)
+}
+
+func TestDoWithContext(t *testing.T) {
+	blocking := func(ctx context.Context) (int, error) {
+		<-ctx.Done()
+		return 1, ctx.Err()
+	}
+
+	//the deadline comes from the context
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	result, err := Do(ctx, blocking, WithRetry(RetryConfig{MaxRetry: 3}))
+	assert.Equal(t, 0, result)
+	assert.Equal(t, true, errors.Is(err, ErrDeadline))
+
+	//the cancellation as well
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	_, err = Do(ctx, blocking)
+	assert.Equal(t, true, errors.Is(err, context.Canceled))
+}
+
+func TestDoHedging(t *testing.T) {
+	//the slow attempt still returns its value after it's cancelled, but the hedged one wins
+	clock := NewFakeClock(time.Now())
+	job := func(ctx context.Context) (string, error) {
+		attempt, _ := AttemptFromContext(ctx)
+		if attempt.Number == 1 {
+			<-ctx.Done()
+			return "slow", nil
+		}
+		return "fast", nil
+	}
+
+	winner := make(chan int, 1)
+	result := make(chan string, 1)
+	go func() {
+		value, err := Do(context.Background(), job, WithClock(clock), WithHedging(time.Second, 2),
+			WithHooks(Hooks{OnSuccess: func(attempt Attempt) { winner <- attempt.Number }}))
+		assert.NoError(t, err)
+		result <- value
+	}()
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Second)
+	assert.Equal(t, "fast", <-result)
+	//the hook of the option is still called
+	assert.Equal(t, 2, <-winner)
+}
+
+func TestDoHedgingIgnoredContext(t *testing.T) {
+	//the slow attempt ignores the cancellation, the winner is still returned right away
+	clock := NewFakeClock(time.Now())
+	release := make(chan struct{})
+	defer close(release)
+	job := func(ctx context.Context) (string, error) {
+		attempt, _ := AttemptFromContext(ctx)
+		if attempt.Number == 1 {
+			<-release
+			return "slow", nil
+		}
+		return "fast", nil
+	}
+
+	result := make(chan string, 1)
+	go func() {
+		value, err := Do(context.Background(), job, WithClock(clock), WithHedging(time.Second, 2))
+		assert.NoError(t, err)
+		result <- value
+	}()
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Second)
+	assert.Equal(t, "fast", <-result)
+}
@@ -0,0 +1,122 @@
+//daylight saving time policy for the wall clock schedules
+//daily, weekly and cron schedules are defined in local wall clock time
+//twice a year in zones with daylight saving time such wall clock time might not exist
+//(the clock jumps forward, e.g. 02:30 in Europe/Berlin on the last sunday of march)
+//or might happen twice (the clock jumps back, e.g. 02:30 on the last sunday of october)
+//the policy decides what to do in both cases
+//hourly and custom interval schedules are counted in absolute time, so they are not affected
+package gover
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+//what to do with a wall clock time that doesn't exist
+type DSTGapPolicy int
+
+const (
+	//run the job shifted forward by the length of the gap
+	//e.g. 02:30 becomes 03:30 when the clock jumps from 02:00 to 03:00
+	GapShiftForward DSTGapPolicy = iota
+	//don't run the job on that day at all
+	GapSkip
+)
+
+//what to do with a wall clock time that happens twice
+type DSTFoldPolicy int
+
+const (
+	//run the job only on the first occurrence (still in summer time)
+	FoldFirst DSTFoldPolicy = iota
+	//run the job only on the second occurrence (already in winter time)
+	FoldSecond
+	//run the job on both occurrences
+	FoldBoth
+)
+
+//the policy for both nonexistent and ambiguous wall clock time
+//the zero value shifts the nonexistent time forward and runs only on the first ambiguous time
+type DSTPolicy struct {
+	Gap  DSTGapPolicy
+	Fold DSTFoldPolicy
+}
+
+//make sure that the policy contains only known values
+func (p DSTPolicy) validate() error {
+	if p.Gap < GapShiftForward || p.Gap > GapSkip {
+		return fmt.Errorf("Invalid DST gap policy: %d", p.Gap)
+	}
+	if p.Fold < FoldFirst || p.Fold > FoldBoth {
+		return fmt.Errorf("Invalid DST fold policy: %d", p.Fold)
+	}
+	return nil
+}
+
+//resolve a wall clock time in the location into the instants the job should run
+//normally it is exactly one instant, but there can be none or two around DST transition
+//the result is sorted from the earliest
+func (p DSTPolicy) resolve(year int, month time.Month, day, hour, min, sec, nsec int, loc *time.Location) []time.Time {
+	//the wall clock time as if it was in UTC
+	//it is also used to normalize overflowing day (e.g. 32nd of january)
+	wall := time.Date(year, month, day, hour, min, sec, nsec, time.UTC)
+
+	//the offsets of the location a day before and a day after
+	//assume that there's at most one transition in between
+	_, offsetBefore := wall.Add(-24 * time.Hour).In(loc).Zone()
+	_, offsetAfter := wall.Add(24 * time.Hour).In(loc).Zone()
+
+	//collect the instants that really show this wall clock time in the location
+	var result []time.Time
+	for _, offset := range []int{offsetBefore, offsetAfter} {
+		candidate := wall.Add(-time.Duration(offset) * time.Second).In(loc)
+		if !sameWallClock(candidate, wall) {
+			continue
+		}
+		if len(result) == 1 && result[0].Equal(candidate) {
+			continue
+		}
+		result = append(result, candidate)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Before(result[j]) })
+
+	switch len(result) {
+	case 0:
+		//nonexistent wall clock time
+		//using the offset before the transition shifts it forward by the length of the gap
+		if p.Gap == GapSkip {
+			return nil
+		}
+		return []time.Time{wall.Add(-time.Duration(offsetBefore) * time.Second).In(loc)}
+	case 2:
+		//ambiguous wall clock time
+		switch p.Fold {
+		case FoldFirst:
+			return result[:1]
+		case FoldSecond:
+			return result[1:]
+		}
+	}
+
+	return result
+}
+
+//check whether the time shows exactly the same wall clock time as the input in UTC
+func sameWallClock(t, wall time.Time) bool {
+	y1, m1, d1 := t.Date()
+	y2, m2, d2 := wall.Date()
+	return y1 == y2 && m1 == m2 && d1 == d2 &&
+		t.Hour() == wall.Hour() && t.Minute() == wall.Minute() && t.Second() == wall.Second()
+}
+
+//get the first resolved instant of the wall clock time that is strictly after the input time
+//return zero time if there's none
+func (p DSTPolicy) firstAfter(after time.Time, year int, month time.Month, day, hour, min, sec, nsec int, loc *time.Location) time.Time {
+	for _, candidate := range p.resolve(year, month, day, hour, min, sec, nsec, loc) {
+		if candidate.After(after) {
+			return candidate
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,189 @@
+package gover
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+//in Europe/Berlin 2021 the clock jumps from 02:00 to 03:00 on 28th march
+//and back from 03:00 to 02:00 on 31st october
+func berlinTime(t *testing.T) (*time.Location, func(string) time.Time) {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	assert.NoError(t, err)
+	return loc, func(s string) time.Time {
+		result, _ := time.Parse("2006-01-02 15:04 MST", s)
+		return result
+	}
+}
+
+func TestDSTPolicyResolve(t *testing.T) {
+	loc, utc := berlinTime(t)
+
+	//normal wall clock time
+	result := DSTPolicy{}.resolve(2021, time.March, 27, 2, 30, 0, 0, loc)
+	assert.Equal(t, 1, len(result))
+	assert.Equal(t, utc("2021-03-27 01:30 UTC").Unix(), result[0].Unix())
+
+	//nonexistent time
+	result = DSTPolicy{}.resolve(2021, time.March, 28, 2, 30, 0, 0, loc)
+	assert.Equal(t, 1, len(result))
+	assert.Equal(t, utc("2021-03-28 01:30 UTC").Unix(), result[0].Unix())
+	assert.Equal(t, "03:30", result[0].Format("15:04"))
+
+	result = DSTPolicy{Gap: GapSkip}.resolve(2021, time.March, 28, 2, 30, 0, 0, loc)
+	assert.Equal(t, 0, len(result))
+
+	//ambiguous time
+	result = DSTPolicy{}.resolve(2021, time.October, 31, 2, 30, 0, 0, loc)
+	assert.Equal(t, 1, len(result))
+	assert.Equal(t, utc("2021-10-31 00:30 UTC").Unix(), result[0].Unix())
+
+	result = DSTPolicy{Fold: FoldSecond}.resolve(2021, time.October, 31, 2, 30, 0, 0, loc)
+	assert.Equal(t, 1, len(result))
+	assert.Equal(t, utc("2021-10-31 01:30 UTC").Unix(), result[0].Unix())
+
+	result = DSTPolicy{Fold: FoldBoth}.resolve(2021, time.October, 31, 2, 30, 0, 0, loc)
+	assert.Equal(t, 2, len(result))
+	assert.Equal(t, utc("2021-10-31 00:30 UTC").Unix(), result[0].Unix())
+	assert.Equal(t, utc("2021-10-31 01:30 UTC").Unix(), result[1].Unix())
+}
+
+func TestDSTPolicyValidate(t *testing.T) {
+	assert.NoError(t, DSTPolicy{Gap: GapSkip, Fold: FoldBoth}.validate())
+	assert.Error(t, DSTPolicy{Gap: DSTGapPolicy(5)}.validate())
+	assert.Error(t, DSTPolicy{Fold: DSTFoldPolicy(-1)}.validate())
+
+	_, err := NewDaily(randomFunc, "0230", globalTimeLoc, WithDSTPolicy(DSTPolicy{Fold: DSTFoldPolicy(3)}))
+	assert.Error(t, err)
+
+	_, err = NewCrontab(globalTimeLoc, WithDSTPolicy(DSTPolicy{Gap: DSTGapPolicy(2)}))
+	assert.Error(t, err)
+
+	gt, err := NewDaily(randomFunc, "0230", globalTimeLoc, WithDSTPolicy(DSTPolicy{Gap: GapSkip}))
+	assert.NoError(t, err)
+	assert.Equal(t, GapSkip, gt.jobInterval.(dailyJob).dstPolicy.Gap)
+}
+
+func TestDailyAcrossDST(t *testing.T) {
+	loc, utc := berlinTime(t)
+
+	//the wall clock time stays the same, so the day has only 23 hours
+	dj, _ := newDailyJob("1330", loc, DSTPolicy{})
+	from := utc("2021-03-27 12:30 UTC")
+	next := dj.Next(from)
+	assert.Equal(t, utc("2021-03-28 11:30 UTC").Unix(), next.Unix())
+	assert.Equal(t, float64(23), next.Sub(from).Hours())
+
+	//and 25 hours in autumn
+	from = utc("2021-10-30 11:30 UTC")
+	next = dj.Next(from)
+	assert.Equal(t, utc("2021-10-31 12:30 UTC").Unix(), next.Unix())
+	assert.Equal(t, float64(25), next.Sub(from).Hours())
+
+	//nonexistent time
+	dj, _ = newDailyJob("0230", loc, DSTPolicy{})
+	next = dj.Next(utc("2021-03-27 02:00 UTC"))
+	assert.Equal(t, utc("2021-03-28 01:30 UTC").Unix(), next.Unix())
+
+	dj, _ = newDailyJob("0230", loc, DSTPolicy{Gap: GapSkip})
+	next = dj.Next(utc("2021-03-27 02:00 UTC"))
+	assert.Equal(t, utc("2021-03-29 00:30 UTC").Unix(), next.Unix())
+
+	//ambiguous time
+	dj, _ = newDailyJob("0230", loc, DSTPolicy{Fold: FoldBoth})
+	next = dj.Next(utc("2021-10-30 23:00 UTC"))
+	assert.Equal(t, utc("2021-10-31 00:30 UTC").Unix(), next.Unix())
+	next = dj.Next(next)
+	assert.Equal(t, utc("2021-10-31 01:30 UTC").Unix(), next.Unix())
+	next = dj.Next(next)
+	assert.Equal(t, utc("2021-11-01 01:30 UTC").Unix(), next.Unix())
+
+	dj, _ = newDailyJob("0230", loc, DSTPolicy{Fold: FoldSecond})
+	next = dj.Next(utc("2021-10-30 23:00 UTC"))
+	assert.Equal(t, utc("2021-10-31 01:30 UTC").Unix(), next.Unix())
+
+	//immediately running daily job keeps its wall clock time
+	dj, _ = newDailyJob("", loc, DSTPolicy{})
+	next = dj.Next(utc("2021-03-27 09:15 UTC"))
+	assert.Equal(t, utc("2021-03-28 08:15 UTC").Unix(), next.Unix())
+
+	//even if one of the runs is shifted
+	start := utc("2021-03-27 01:30 UTC")
+	schedule := dj.startingAt(start)
+	next = schedule.Next(start)
+	assert.Equal(t, "03:30", next.In(loc).Format("15:04"))
+	next = schedule.Next(next)
+	assert.Equal(t, utc("2021-03-29 00:30 UTC").Unix(), next.Unix())
+	assert.Equal(t, "02:30", next.In(loc).Format("15:04"))
+}
+
+func TestWeeklyAcrossDST(t *testing.T) {
+	loc, utc := berlinTime(t)
+
+	wj, _ := newWeeklyJob("Sunday@0230", loc, DSTPolicy{Gap: GapSkip})
+	next := wj.Next(utc("2021-03-26 12:00 UTC"))
+	assert.Equal(t, utc("2021-04-04 00:30 UTC").Unix(), next.Unix())
+
+	wj, _ = newWeeklyJob("Sunday@0230", loc, DSTPolicy{})
+	next = wj.Next(utc("2021-03-26 12:00 UTC"))
+	assert.Equal(t, utc("2021-03-28 01:30 UTC").Unix(), next.Unix())
+
+	wj, _ = newWeeklyJob("Sunday@0230", loc, DSTPolicy{Fold: FoldBoth})
+	next = wj.Next(utc("2021-10-31 00:30 UTC"))
+	assert.Equal(t, utc("2021-10-31 01:30 UTC").Unix(), next.Unix())
+}
+
+func TestCronAcrossDST(t *testing.T) {
+	loc, utc := berlinTime(t)
+
+	//every half an hour between 01:00 and 03:59
+	cj, _ := parseCronExpression("*/30 1-3 * * *", loc, DSTPolicy{Fold: FoldBoth})
+	var runs []string
+	next := utc("2021-10-30 22:59 UTC")
+	for i := 0; i < 8; i++ {
+		next = cj.Next(next)
+		runs = append(runs, next.UTC().Format("15:04"))
+	}
+	assert.Equal(t, []string{"23:00", "23:30", "00:00", "00:30", "01:00", "01:30", "02:00", "02:30"}, runs)
+
+	cj, _ = parseCronExpression("*/30 1-3 * * *", loc, DSTPolicy{})
+	runs = nil
+	next = utc("2021-10-30 22:59 UTC")
+	for i := 0; i < 6; i++ {
+		next = cj.Next(next)
+		runs = append(runs, next.UTC().Format("15:04"))
+	}
+	assert.Equal(t, []string{"23:00", "23:30", "00:00", "00:30", "02:00", "02:30"}, runs)
+
+	//the skipped hour in spring
+	cj, _ = parseCronExpression("30 2 * * *", loc, DSTPolicy{Gap: GapSkip})
+	next = cj.Next(utc("2021-03-27 12:00 UTC"))
+	assert.Equal(t, utc("2021-03-29 00:30 UTC").Unix(), next.Unix())
+
+	cj, _ = parseCronExpression("30 2 * * *", loc, DSTPolicy{})
+	next = cj.Next(utc("2021-03-27 12:00 UTC"))
+	assert.Equal(t, utc("2021-03-28 01:30 UTC").Unix(), next.Unix())
+}
+
+func TestDailyStartAcrossDST(t *testing.T) {
+	loc, utc := berlinTime(t)
+
+	//started at 02:30 the day before the gap, the run at the gap is shifted to 03:30
+	clock := NewFakeClock(utc("2021-03-27 01:30 UTC"))
+	var runs []string
+	gt, err := NewDaily(func(ctx context.Context) {}, "", loc, WithClock(clock))
+	assert.NoError(t, err)
+	assert.NoError(t, gt.Start())
+	defer gt.Stop()
+
+	next := clock.Now()
+	for i := 0; i < 2; i++ {
+		next = gt.schedule.Next(next)
+		runs = append(runs, next.In(loc).Format("2006-01-02 15:04"))
+	}
+	//and the following ones are back at 02:30
+	assert.Equal(t, []string{"2021-03-28 03:30", "2021-03-29 02:30"}, runs)
+}
@@ -1,12 +1,89 @@
 //collection of errors
 package gover
 
-import "errors"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
 
 var (
-	StartingPointError = errors.New("Starting point is not valid")
-	TimeLocationError  = errors.New("Time location is not defined")
-	KeyNotFoundError   = errors.New("Unable to locate this key")
-	DuplicateKeyError  = errors.New("This key is identified as duplicate")
-	InterfaceTypeError = errors.New("Invalid type interface")
+	StartingPointError  = errors.New("Starting point is not valid")
+	TimeLocationError   = errors.New("Time location is not defined")
+	KeyNotFoundError    = errors.New("Unable to locate this key")
+	DuplicateKeyError   = errors.New("This key is identified as duplicate")
+	InterfaceTypeError  = errors.New("Invalid type interface")
+	CronExpressionError = errors.New("Cron expression is not valid")
+	ClockError          = errors.New("Clock is not defined")
+
+	SchedulerActiveError   = errors.New("The scheduler is still active currently")
+	SchedulerInactiveError = errors.New("The scheduler is already inactive")
+
+	//reasons why gover stops retrying, can be checked on RetryError with errors.Is
+	ErrMaxRetry     = errors.New("Maximum number of retry exceeded")
+	ErrDeadline     = errors.New("Deadline is exceeded")
+	ErrNonRetryable = errors.New("Error is not retryable")
+	ErrCircuitOpen  = errors.New("Circuit breaker is open")
+
+	ErrRetryBudgetExhausted = errors.New("Retry budget is exhausted")
+	ErrBulkheadFull         = errors.New("Bulkhead is full")
 )
+
+//returned by shutdown if some gotermins are not finished in time
+type ShutdownError struct {
+	//keys of the gotermins whose jobs are still running
+	Keys []string
+	//the reason why it stopped waiting (the context error)
+	Err error
+}
+
+func (se *ShutdownError) Error() string {
+	return fmt.Sprintf("Shutdown is not finished for keys [%s]: %v", strings.Join(se.Keys, ", "), se.Err)
+}
+
+func (se *ShutdownError) Unwrap() error { return se.Err }
+
+//returned by gover if the job is not successful
+//errors.Is matches both the reason (e.g. ErrMaxRetry) and the last error of the job
+type RetryError struct {
+	//why it stopped retrying: ErrMaxRetry, ErrDeadline, ErrNonRetryable, ErrCircuitOpen,
+	//ErrRetryBudgetExhausted, ErrBulkheadFull or the context error
+	Reason error
+	//all failed attempts from the first one
+	Attempts []FailedAttempt
+}
+
+//a single failed attempt of the job
+type FailedAttempt struct {
+	//the error returned by the job or the context error if it's timed out
+	Err error
+	//how long the attempt lasted
+	Duration time.Duration
+}
+
+func (re *RetryError) Error() string {
+	lastErr := re.Unwrap()
+	if lastErr == nil {
+		return fmt.Sprintf("%v (attempts: %d)", re.Reason, len(re.Attempts))
+	}
+	return fmt.Sprintf("%v (attempts: %d): %v", re.Reason, len(re.Attempts), lastErr)
+}
+
+//the error of the last attempt, nil if there's none
+func (re *RetryError) Unwrap() error {
+	if len(re.Attempts) == 0 {
+		return nil
+	}
+	return re.Attempts[len(re.Attempts)-1].Err
+}
+
+//match the reason, the last error is matched through Unwrap
+//the exceeded deadline matches context.DeadlineExceeded as well
+func (re *RetryError) Is(target error) bool {
+	if re.Reason == ErrDeadline && target == context.DeadlineExceeded {
+		return true
+	}
+	return errors.Is(re.Reason, target)
+}
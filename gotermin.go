@@ -1,12 +1,14 @@
 //GoTermin is in principal a cronjob like scheduler
 //it will do its assigned on a certain schedule
 //the job function in this case is a simple func(context.Context) without returning anything
+//or func(context.Context) error if the result of the job should be recorded
 package gover
 
 import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -14,25 +16,114 @@ type Gotermin struct {
 	//the job that's supposed to be done
 	//it will run on separate thread
 	//the job has context as input so it can handle the timeout from each interval
+	//for the gotermin created with the job with error it calls that job and drops the error
 	Job func(ctx context.Context)
-	//channel to stop the job
+	//the job of the constructors with error, its error is recorded in the stats
+	//nil if the gotermin is created with the job without error, then Job is run
+	errJob func(ctx context.Context) error
+	//channel to stop the loop, created on every start
 	quit chan interface{}
-	//interval to decide the context timeout
-	//this interface contain the interval and sleep duration
+	//interval to decide when the job should run
+	//by default the job context lasts until the next run
 	jobInterval interval
+	//source of the time to decide when the job should run
+	clock Clock
+	//what to do if the previous run is still running when the next one is due
+	overlapPolicy OverlapPolicy
+	//receives the recovered panics of the job, the schedule keeps running
+	panicHandler func(error)
+	//the validated options, the gover that retries each run is created from them
+	//the failed job is only retried if cfg.retry is set
+	cfg config
+	//key in the crontab, empty if it's not registered in any
+	key string
+
+	//protect the state of the scheduler and the runs below
+	//the gotermin is safe to be used from multiple goroutines
+	mu sync.Mutex
 	//indicator whether it's still running or not
 	isActive bool
+	//cancel all running jobs of the current start
+	cancelRuns context.CancelFunc
+	//closed once the loop of the current start is finished
+	loopDone chan struct{}
+	//the schedule of the current start, see Start
+	schedule interval
+	//number of jobs that are still running
+	running int
+	//closed once there's no running job anymore, nil if nothing is running
+	idle chan struct{}
+	//cancel functions of the running jobs, by run id
+	runCancels map[int64]context.CancelFunc
+	//whether a run is waiting for the running one to finish
+	queued bool
+	//statistic of the runs
+	stats Stats
+}
+
+//statistic of a gotermin since it's created
+type Stats struct {
+	//number of started runs
+	Runs int64
+	//number of runs skipped because the previous one was still running
+	Skipped int64
+	//number of runs that waited for the previous one to finish
+	Queued int64
+	//number of runs that cancelled the previous one
+	Replaced int64
+	//number of runs (including the retrials) that panicked
+	Panics int64
+	//number of runs that returned an error or panicked
+	Failures int64
+	//the error of the last failed run, nil if none has failed yet
+	LastError error
+	//when the last failed run finished, zero if none has failed yet
+	LastFailure time.Time
+	//when the last successful run finished, zero if none has succeeded yet
+	LastSuccess time.Time
+}
+
+//keep the job without error in Job, so replacing Job still changes what runs
+//the gotermin is nil if the constructor failed
+func (gt *Gotermin) withJob(job func(context.Context)) *Gotermin {
+	if gt != nil {
+		gt.Job, gt.errJob = job, nil
+	}
+	return gt
+}
+
+//the job that's run, the one with error if the gotermin is created with it
+func (gt *Gotermin) job() func(context.Context) error {
+	if gt.errJob != nil {
+		return gt.errJob
+	}
+	return withoutError(gt.Job)
+}
+
+//turn a job without result into a job that never fails
+func withoutError(job func(context.Context)) func(context.Context) error {
+	return func(ctx context.Context) error {
+		job(ctx)
+		return nil
+	}
 }
 
 //this should setup a gotermin, which will run in 1 hour interval
 //input minute decide when (minute) the schedule should be started (number between 00-60)
 //if input minute is an empty string, start the job immediately
 //also determine the time location to make sure it's running properly
-func NewHourly(job func(context.Context), minute string, loc *time.Location) (*Gotermin, error) {
+func NewHourly(job func(context.Context), minute string, loc *time.Location, opts ...Option) (*Gotermin, error) {
+	gt, err := NewHourlyWithError(withoutError(job), minute, loc, opts...)
+	return gt.withJob(job), err
+}
+
+//the same as NewHourly, but the job returns an error
+//the last error and the last success are recorded in the stats
+func NewHourlyWithError(job func(context.Context) error, minute string, loc *time.Location, opts ...Option) (*Gotermin, error) {
 	//return error if minute is not a valid minute string
 	//add exception for empty string
 	if _, err := time.Parse("04", minute); err != nil && minute != "" {
-		return nil, fmt.Errorf("Please input minute between 00-59", minute)
+		return nil, fmt.Errorf("Please input minute between 00-59")
 	}
 
 	//also return error if time location is nil
@@ -40,18 +131,31 @@ func NewHourly(job func(context.Context), minute string, loc *time.Location) (*G
 		return nil, fmt.Errorf("Please input a valid time location")
 	}
 
-	return &Gotermin{
-		Job:         job,
-		quit:        make(chan interface{}, 1),
-		jobInterval: hourlyJob{minute, loc},
-	}, nil
+	cfg, err := newConfig(targetScheduler, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	hourly, err := newHourlyJob(minute, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	return newGotermin(job, hourly, cfg), nil
 }
 
 //this function will schedule the job in daily interval
 //input hour will decide when the schedule should be started
 //the hour should be in form hhmm, if it's not parseable then return error
 //also determine the time location to make sure it's running properly
-func NewDaily(job func(context.Context), hour string, loc *time.Location) (*Gotermin, error) {
+func NewDaily(job func(context.Context), hour string, loc *time.Location, opts ...Option) (*Gotermin, error) {
+	gt, err := NewDailyWithError(withoutError(job), hour, loc, opts...)
+	return gt.withJob(job), err
+}
+
+//the same as NewDaily, but the job returns an error
+//the last error and the last success are recorded in the stats
+func NewDailyWithError(job func(context.Context) error, hour string, loc *time.Location, opts ...Option) (*Gotermin, error) {
 	//return error if hour is not a valid hour string
 	//add exception for empty string (the schedule will run immediately)
 	if _, err := time.Parse("1504", hour); err != nil && hour != "" {
@@ -63,17 +167,30 @@ func NewDaily(job func(context.Context), hour string, loc *time.Location) (*Gote
 		return nil, fmt.Errorf("Please input a valid time location")
 	}
 
-	return &Gotermin{
-		Job:         job,
-		quit:        make(chan interface{}, 1),
-		jobInterval: dailyJob{hour, loc},
-	}, nil
+	cfg, err := newConfig(targetScheduler, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	daily, err := newDailyJob(hour, loc, cfg.dstPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	return newGotermin(job, daily, cfg), nil
 }
 
 //this function will schedule the job in weekly interval
 //input weekday is in format of "weekday hour" separated by @ symbol (e.g. "Monday@1530")
 //if input is not valid then an error will be returned
-func NewWeekly(job func(context.Context), weekly string, loc *time.Location) (*Gotermin, error) {
+func NewWeekly(job func(context.Context), weekly string, loc *time.Location, opts ...Option) (*Gotermin, error) {
+	gt, err := NewWeeklyWithError(withoutError(job), weekly, loc, opts...)
+	return gt.withJob(job), err
+}
+
+//the same as NewWeekly, but the job returns an error
+//the last error and the last success are recorded in the stats
+func NewWeeklyWithError(job func(context.Context) error, weekly string, loc *time.Location, opts ...Option) (*Gotermin, error) {
 	//weekly string should contains exactly 2 elements after splitted by @
 	weeklySplitted := strings.Split(weekly, "@")
 	if len(weeklySplitted) != 2 {
@@ -81,7 +198,7 @@ func NewWeekly(job func(context.Context), weekly string, loc *time.Location) (*G
 	}
 
 	//validate the week first
-	if _, err := getWeekDuration(weeklySplitted[0]); err != nil {
+	if _, err := parseWeekday(weeklySplitted[0]); err != nil {
 		return nil, err
 	}
 
@@ -95,17 +212,62 @@ func NewWeekly(job func(context.Context), weekly string, loc *time.Location) (*G
 		return nil, fmt.Errorf("Please input a valid time location")
 	}
 
-	return &Gotermin{
-		Job:         job,
-		quit:        make(chan interface{}, 1),
-		jobInterval: weeklyJob{weekly, loc},
-	}, nil
+	cfg, err := newConfig(targetScheduler, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	weeklyInterval, err := newWeeklyJob(weekly, loc, cfg.dstPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	return newGotermin(job, weeklyInterval, cfg), nil
+}
+
+//this function will schedule the job in monthly interval
+//input is in format of "day hour" separated by @ symbol, where the day is either
+//the day of month (e.g. "1@0200"), the last day of month ("L@2300")
+//or the nth weekday of month (e.g. "Tuesday#2@0900" or "Friday#L@1700" for the last friday)
+//months without the selected day (e.g. 31st) are skipped
+//if input is not valid then an error will be returned
+func NewMonthly(job func(context.Context), monthly string, loc *time.Location, opts ...Option) (*Gotermin, error) {
+	gt, err := NewMonthlyWithError(withoutError(job), monthly, loc, opts...)
+	return gt.withJob(job), err
+}
+
+//the same as NewMonthly, but the job returns an error
+//the last error and the last success are recorded in the stats
+func NewMonthlyWithError(job func(context.Context) error, monthly string, loc *time.Location, opts ...Option) (*Gotermin, error) {
+	//return error if location is nil
+	if loc == nil {
+		return nil, fmt.Errorf("Please input a valid time location")
+	}
+
+	cfg, err := newConfig(targetScheduler, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	monthlyInterval, err := newMonthlyJob(monthly, loc, cfg.dstPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	return newGotermin(job, monthlyInterval, cfg), nil
 }
 
 //this function will set the schedule interval at will
 //however the starting point can't be set (i.e. the job will start immediately)
 //and the custom interval can't be less than 1 second
-func NewCustomInterval(job func(context.Context), interval time.Duration, loc *time.Location) (*Gotermin, error) {
+func NewCustomInterval(job func(context.Context), interval time.Duration, loc *time.Location, opts ...Option) (*Gotermin, error) {
+	gt, err := NewCustomIntervalWithError(withoutError(job), interval, loc, opts...)
+	return gt.withJob(job), err
+}
+
+//the same as NewCustomInterval, but the job returns an error
+//the last error and the last success are recorded in the stats
+func NewCustomIntervalWithError(job func(context.Context) error, interval time.Duration, loc *time.Location, opts ...Option) (*Gotermin, error) {
 	//return error if location is nil
 	if loc == nil {
 		return nil, fmt.Errorf("Please input a valid time location")
@@ -116,91 +278,328 @@ func NewCustomInterval(job func(context.Context), interval time.Duration, loc *t
 		return nil, fmt.Errorf("Please insert duration greater than 1 second")
 	}
 
+	cfg, err := newConfig(targetScheduler, opts)
+	if err != nil {
+		return nil, err
+	}
+
 	//set the interval category into custom
 	//set the custom interval into desired interval
-	return &Gotermin{
-		Job:         job,
-		quit:        make(chan interface{}, 1),
-		jobInterval: customIntervalJob{interval},
-	}, nil
+	return newGotermin(job, customIntervalJob{interval}, cfg), nil
+}
+
+//this function will schedule the job based on a standard five fields cron expression
+//e.g. "*/15 9-17 * * MON-FRI" or a macro like "@daily"
+//the expression is evaluated in the given time location
+func NewCron(job func(context.Context), expression string, loc *time.Location, opts ...Option) (*Gotermin, error) {
+	gt, err := NewCronWithError(withoutError(job), expression, loc, opts...)
+	return gt.withJob(job), err
+}
+
+//the same as NewCron, but the job returns an error
+//the last error and the last success are recorded in the stats
+func NewCronWithError(job func(context.Context) error, expression string, loc *time.Location, opts ...Option) (*Gotermin, error) {
+	//return error if location is nil
+	if loc == nil {
+		return nil, fmt.Errorf("Please input a valid time location")
+	}
+
+	cfg, err := newConfig(targetScheduler, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	cron, err := parseCronExpression(expression, loc, cfg.dstPolicy)
+	if err != nil {
+		return nil, err
+	}
 
+	return newGotermin(job, cron, cfg), nil
+}
+
+//create the gotermin with the validated interval and options
+func newGotermin(job func(context.Context) error, jobInterval interval, cfg config) *Gotermin {
+	panicHandler := cfg.panicHandler
+	if panicHandler == nil {
+		panicHandler = printPanic
+	}
+
+	return &Gotermin{
+		Job:           func(ctx context.Context) { job(ctx) },
+		errJob:        job,
+		quit:          make(chan interface{}, 1),
+		jobInterval:   jobInterval,
+		clock:         cfg.clock,
+		overlapPolicy: cfg.overlapPolicy,
+		panicHandler:  panicHandler,
+		cfg:           cfg,
+		runCancels:    map[int64]context.CancelFunc{},
+	}
 }
 
 //stop the currently running go termin
+//the running jobs are cancelled as well
 func (gt *Gotermin) Stop() error {
+	gt.mu.Lock()
+	defer gt.mu.Unlock()
+
 	if !gt.isActive {
-		return fmt.Errorf("The scheduler is already inactive")
+		return SchedulerInactiveError
 	}
+
+	gt.stopLocked()
+	return nil
+}
+
+//stop the loop and cancel the running jobs, the lock should be already held
+func (gt *Gotermin) stopLocked() {
+	gt.isActive = false
+	gt.cancelRuns()
 	gt.quit <- "stop"
+}
+
+//stop the scheduler if it's still active and cancel the running jobs
+//then wait until all of them have returned or the context is done
+//return the context error if they are not finished in time
+func (gt *Gotermin) Shutdown(ctx context.Context) error {
+	gt.mu.Lock()
+	if gt.isActive {
+		gt.stopLocked()
+	}
+	loopDone, idle := gt.loopDone, gt.idle
+	gt.mu.Unlock()
+
+	for _, done := range []chan struct{}{loopDone, idle} {
+		if done == nil {
+			continue
+		}
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
 	return nil
 }
 
+//whether the scheduler is currently active
+func (gt *Gotermin) IsActive() bool {
+	gt.mu.Lock()
+	defer gt.mu.Unlock()
+	return gt.isActive
+}
+
 //run the scheduler
 //validate etc before starting the loop
 func (gt *Gotermin) Start() error {
+	gt.mu.Lock()
+	defer gt.mu.Unlock()
+
 	//validate the entry again
 	//return error if it's still active
 	if gt.isActive {
-		return fmt.Errorf("The scheduler is still active currently")
+		return SchedulerActiveError
 	}
 
-	//check the interval category
-	//return error if it's not valid
-	//also check the respective starting point altogether
-	jobInterval := gt.jobInterval.getInterval()
+	//now decide when the first run should happen
+	//either right away or on the next run of the schedule
+	//the schedule that runs immediately is anchored at the first run
+	firstRun := gt.clock.Now()
+	schedule := gt.jobInterval
+	if !schedule.runsImmediately() {
+		firstRun = schedule.Next(firstRun)
+	} else if started, ok := schedule.(startedInterval); ok {
+		schedule = started.startingAt(firstRun)
+	}
 
-	//now decide how long we should wait until the starting point
-	//use time sleep for this action
-	//determine the sleep duration from category
-	sleepDuration, err := gt.jobInterval.getSleepDuration(time.Now())
-	if err != nil {
-		return err
+	//return error if the schedule will never run
+	if firstRun.IsZero() {
+		return fmt.Errorf("The schedule %s will never run", gt.jobInterval)
 	}
 
+	//set the status into running already here, so starting it twice is not possible
+	//all jobs are derived from the run context, so they are cancelled once it's stopped
+	//every start has its own quit channel, so a stopping loop can't take the signal of the new one
+	runCtx, cancelRuns := context.WithCancel(context.Background())
+	gt.isActive = true
+	gt.cancelRuns = cancelRuns
+	gt.quit = make(chan interface{}, 1)
+	gt.loopDone = make(chan struct{})
+	gt.schedule = schedule
+
 	//if there is nothing wrong then start the job
-	go gt.start(jobInterval, sleepDuration)
+	go gt.start(runCtx, gt.quit, gt.loopDone, schedule, firstRun)
 
 	return nil
 }
 
-func (gt *Gotermin) start(jobInterval, sleepDuration time.Duration) {
-	//first of all set the status into running
-	gt.isActive = true
-
-	//then sleep for the assigned sleep duration
-	wakeUp := time.After(sleepDuration)
-
-	//return if signal quit is received
-	select {
-	case <-gt.quit:
-		//return and set the status into inactive
-		gt.isActive = false
-		return
-	case <-wakeUp:
-		//continue to start the job periodically
-	}
+func (gt *Gotermin) start(runCtx context.Context, quit chan interface{}, loopDone chan struct{}, schedule interval, nextRun time.Time) {
+	defer close(loopDone)
 
-	//after awoken from the slumber
-	//start an infinite loop with the job interval
+	//sleep until the next run, which is followed by the next one of the schedule
 	for {
-		//create new context to make sure the job interval works as planned
-		ctx, cancel := context.WithTimeout(context.Background(), jobInterval)
-
-		//then simply do the job in different thread
-		go gt.Job(ctx)
+		//if the schedule will never run again, simply wait until it's stopped
+		var wakeUp <-chan time.Time
+		var timer Timer
+		if !nextRun.IsZero() {
+			timer = gt.clock.NewTimer(nextRun.Sub(gt.clock.Now()))
+			wakeUp = timer.C()
+		}
 
-		//wait until either context is timed out or it's stopped
+		//wait until either it's time to run or it's stopped
 		select {
-		case <-ctx.Done():
-			continue
-		case signal := <-gt.quit:
+		case signal := <-quit:
 			//if the quite channel is filled, stopping the loop
-			//also cancel the context and set status into inactive
+			//the running jobs are already cancelled on stop
 			fmt.Println("Stopping jobs with signal: ", signal)
-			cancel()
-			gt.isActive = false
+			if timer != nil {
+				timer.Stop()
+			}
 			return
+		case <-wakeUp:
+		}
+
+		//calculate the following run from the planned one, so the schedule doesn't drift
+		//skip the runs that are already missed (e.g. the machine was suspended)
+		followingRun := schedule.Next(nextRun)
+		if now := gt.clock.Now(); !followingRun.IsZero() && !followingRun.After(now) {
+			followingRun = schedule.Next(now)
 		}
 
+		//then simply do the job in different thread
+		gt.dispatch(runCtx, followingRun)
+		nextRun = followingRun
 	}
 }
+
+//run the job based on the overlap policy
+//the deadline is the following run
+func (gt *Gotermin) dispatch(runCtx context.Context, deadline time.Time) {
+	gt.mu.Lock()
+	defer gt.mu.Unlock()
+
+	//it might be stopped while waking up
+	if runCtx.Err() != nil {
+		return
+	}
+
+	if gt.running > 0 {
+		switch gt.overlapPolicy {
+		case OverlapSkip:
+			gt.stats.Skipped++
+			return
+		case OverlapQueue:
+			//only one run can wait, the rest are skipped
+			if gt.queued {
+				gt.stats.Skipped++
+			} else {
+				gt.queued = true
+				gt.stats.Queued++
+			}
+			return
+		case OverlapReplace:
+			for _, cancel := range gt.runCancels {
+				cancel()
+			}
+			gt.stats.Replaced++
+		}
+	}
+
+	gt.launchLocked(runCtx, deadline)
+}
+
+//start the job in different thread, the lock should be already held
+//only concurrent runs have the deadline, otherwise the overlap policy decides how long the job can run
+func (gt *Gotermin) launchLocked(runCtx context.Context, deadline time.Time) {
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if deadline.IsZero() || gt.overlapPolicy != OverlapAllow {
+		ctx, cancel = context.WithCancel(runCtx)
+	} else {
+		ctx, cancel = withClockDeadline(runCtx, gt.clock, deadline)
+	}
+
+	gt.stats.Runs++
+	id := gt.stats.Runs
+	gt.running++
+	gt.runCancels[id] = cancel
+	if gt.idle == nil {
+		gt.idle = make(chan struct{})
+	}
+
+	go func() {
+		defer gt.finish(runCtx, id)
+		gt.run(ctx, deadline)
+	}()
+}
+
+//run the job and record its result
+//hand over the panic to the handler if there's any
+func (gt *Gotermin) run(ctx context.Context, deadline time.Time) {
+	var err error
+	if retry := gt.newRetry(deadline); retry != nil {
+		//the panics of every retrial are handed over by gover
+		err = retry.Run(ctx)
+	} else {
+		err = callSafely(gt.key, func() error {
+			return gt.job()(ctx)
+		})
+		if _, ok := err.(*PanicError); ok {
+			gt.handlePanic(err)
+		}
+	}
+	finishedAt := gt.clock.Now()
+
+	gt.mu.Lock()
+	if err == nil {
+		gt.stats.LastSuccess = finishedAt
+		gt.mu.Unlock()
+		return
+	}
+
+	gt.stats.Failures++
+	gt.stats.LastError = err
+	gt.stats.LastFailure = finishedAt
+	gt.mu.Unlock()
+}
+
+//count the panic and hand it over to the handler
+func (gt *Gotermin) handlePanic(err error) {
+	gt.mu.Lock()
+	gt.stats.Panics++
+	gt.mu.Unlock()
+	gt.panicHandler(err)
+}
+
+//clean up after the job is done
+//start the queued run if there's any and the gotermin is not stopped yet
+func (gt *Gotermin) finish(runCtx context.Context, id int64) {
+	gt.mu.Lock()
+	defer gt.mu.Unlock()
+
+	gt.runCancels[id]()
+	delete(gt.runCancels, id)
+	gt.running--
+
+	//the queued run doesn't know its window yet, so it lasts until the next one is due
+	if gt.queued && gt.running == 0 {
+		gt.queued = false
+		if runCtx.Err() == nil {
+			gt.launchLocked(runCtx, gt.schedule.Next(gt.clock.Now()))
+		}
+	}
+
+	//let the shutdown know that nothing is running anymore
+	if gt.running == 0 {
+		close(gt.idle)
+		gt.idle = nil
+	}
+}
+
+//get the statistic of the runs
+func (gt *Gotermin) Stats() Stats {
+	gt.mu.Lock()
+	defer gt.mu.Unlock()
+	return gt.stats
+}
@@ -2,8 +2,10 @@ package gover
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/stretchr/testify/assert"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -72,6 +74,21 @@ func TestCreateNewWeekly(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestCreateNewMonthly(t *testing.T) {
+	loc, _ := time.LoadLocation("Asia/Jakarta")
+	_, err := NewMonthly(randomFunc, "L 2300", loc)
+	assert.Error(t, err)
+
+	_, err = NewMonthly(randomFunc, "L@2300", nil)
+	assert.Error(t, err)
+
+	_, err = NewMonthly(randomFunc, "Tuesday#2@0961", loc)
+	assert.Error(t, err)
+
+	_, err = NewMonthly(randomFunc, "Tuesday#2@0900", loc)
+	assert.NoError(t, err)
+}
+
 func TestStartAndStopJob(t *testing.T) {
 	c := make(chan interface{}, 1)
 
@@ -108,8 +125,8 @@ func TestCustomInterval(t *testing.T) {
 	initial := int64(0)
 	fmt.Println("initial number", initial)
 	job := func(ctx context.Context) {
-		atomic.AddInt64(&initial, 1)
-		fmt.Println("current number: ", initial)
+		current := atomic.AddInt64(&initial, 1)
+		fmt.Println("current number: ", current)
 	}
 
 	jkt, _ := time.LoadLocation("Asia/Jakarta")
@@ -127,14 +144,206 @@ func TestCustomInterval(t *testing.T) {
 	assert.NoError(t, err)
 	//sleep for 3 seconds
 	//then the initial should be 3 afterwards
+	//the runs happen exactly on the interval, so it's stopped half an interval earlier
 	sleepTime := int64(3)
 
 	sleepDur, _ := time.ParseDuration(fmt.Sprintf("%ds", sleepTime))
-	time.Sleep(sleepDur)
+	time.Sleep(sleepDur - customInterval/2)
 
 	err = result.Stop()
 	assert.NoError(t, err)
+	//wait for the last run before reading the result
+	assert.NoError(t, result.Shutdown(context.Background()))
 	assert.Equal(t, sleepTime, initial)
 }
 
 func randomFunc(ctx context.Context) { fmt.Println("foo") }
+
+func TestCreateNewCron(t *testing.T) {
+	jkt, _ := time.LoadLocation("Asia/Jakarta")
+
+	_, err := NewCron(randomFunc, "*/15 9-17 * * MON-FRI", nil)
+	assert.Error(t, err)
+
+	_, err = NewCron(randomFunc, "*/15 9-17 * *", jkt)
+	assert.Error(t, err)
+
+	result, err := NewCron(randomFunc, "*/15 9-17 * * MON-FRI", jkt)
+	assert.NoError(t, err)
+	assert.Equal(t, "[cron] */15 9-17 * * MON-FRI", result.jobInterval.(cronJob).String())
+
+	//the schedule that never runs can't be started
+	result, err = NewCron(randomFunc, "0 0 31 2 *", jkt)
+	assert.NoError(t, err)
+	err = result.Start()
+	assert.Error(t, err)
+}
+
+func TestGoterminWithFakeClock(t *testing.T) {
+	jkt, _ := time.LoadLocation("Asia/Jakarta")
+	start := time.Date(2016, 11, 4, 10, 20, 0, 0, jkt)
+	clock := NewFakeClock(start)
+
+	runs := make(chan time.Time, 10)
+	job := func(ctx context.Context) {
+		runs <- clock.Now()
+	}
+
+	_, err := NewHourly(job, "30", jkt, WithClock(nil))
+	assert.Error(t, err)
+
+	result, err := NewHourly(job, "30", jkt, WithClock(clock))
+	assert.NoError(t, err)
+	assert.NoError(t, result.Start())
+
+	//the first run is on minute 30
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute * 9)
+	assert.Equal(t, 0, len(runs))
+	clock.Advance(time.Minute)
+	assert.Equal(t, start.Add(time.Minute*10), <-runs)
+
+	//and then every hour
+	for i := 1; i <= 3; i++ {
+		clock.BlockUntil(1)
+		clock.Advance(time.Hour)
+		assert.Equal(t, start.Add(time.Minute*10+time.Hour*time.Duration(i)), <-runs)
+	}
+
+	assert.NoError(t, result.Stop())
+}
+
+func TestConcurrentStartAndStop(t *testing.T) {
+	jkt, _ := time.LoadLocation("Asia/Jakarta")
+	clock := NewFakeClock(time.Now())
+	result, _ := NewCustomInterval(func(ctx context.Context) {}, time.Second, jkt, WithClock(clock))
+
+	var started, stopped int64
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if result.Start() == nil {
+				atomic.AddInt64(&started, 1)
+			}
+			result.IsActive()
+			result.Stats()
+			clock.Advance(time.Second)
+			if result.Stop() == nil {
+				atomic.AddInt64(&stopped, 1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	//every successful start is followed by exactly one successful stop
+	assert.Equal(t, started, stopped)
+	assert.Equal(t, false, result.IsActive())
+}
+
+func TestGoterminShutdown(t *testing.T) {
+	jkt, _ := time.LoadLocation("Asia/Jakarta")
+	clock := NewFakeClock(time.Now())
+
+	//nothing to wait for
+	result, _ := NewCustomInterval(func(ctx context.Context) {}, time.Second, jkt, WithClock(clock))
+	assert.NoError(t, result.Shutdown(context.Background()))
+
+	//the job returns once it's cancelled
+	cancelled := make(chan error, 1)
+	result, _ = NewCustomInterval(func(ctx context.Context) {
+		<-ctx.Done()
+		cancelled <- ctx.Err()
+	}, time.Second, jkt, WithClock(clock))
+	assert.NoError(t, result.Start())
+	eventually(t, func() bool { return result.Stats().Runs == 1 })
+	assert.NoError(t, result.Shutdown(context.Background()))
+	assert.Equal(t, context.Canceled, <-cancelled)
+	assert.Equal(t, false, result.IsActive())
+
+	//the job ignores the cancellation
+	release := make(chan struct{})
+	result, _ = NewCustomInterval(func(ctx context.Context) {
+		<-release
+	}, time.Second, jkt, WithClock(clock))
+	assert.NoError(t, result.Start())
+	eventually(t, func() bool { return result.Stats().Runs == 1 })
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, result.Shutdown(ctx))
+	assert.Equal(t, false, result.IsActive())
+
+	close(release)
+	assert.NoError(t, result.Shutdown(context.Background()))
+}
+
+func TestGoterminWithError(t *testing.T) {
+	start := time.Date(2016, 11, 4, 10, 20, 0, 0, globalTimeLoc)
+	clock := NewFakeClock(start)
+
+	_, err := NewHourlyWithError(func(ctx context.Context) error { return nil }, "60", globalTimeLoc)
+	assert.Error(t, err)
+
+	//the job fails on every second run
+	jobErr := errors.New("no food")
+	runs := make(chan int, 10)
+	tryNum := 0
+	job := func(ctx context.Context) error {
+		tryNum++
+		defer func(n int) { runs <- n }(tryNum)
+		if tryNum%2 == 0 {
+			return jobErr
+		}
+		return nil
+	}
+
+	result, err := NewCustomIntervalWithError(job, time.Minute, globalTimeLoc, WithClock(clock))
+	assert.NoError(t, err)
+	assert.NoError(t, result.Start())
+
+	//the first run is successful
+	<-runs
+	eventually(t, func() bool { return !result.Stats().LastSuccess.IsZero() })
+	stats := result.Stats()
+	assert.Equal(t, start, stats.LastSuccess)
+	assert.Nil(t, stats.LastError)
+	assert.Equal(t, int64(0), stats.Failures)
+
+	//the second one fails, the last success stays
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	<-runs
+	eventually(t, func() bool { return result.Stats().Failures == 1 })
+	stats = result.Stats()
+	assert.Equal(t, jobErr, stats.LastError)
+	assert.Equal(t, start.Add(time.Minute), stats.LastFailure)
+	assert.Equal(t, start, stats.LastSuccess)
+	assert.Equal(t, int64(0), stats.Panics)
+
+	assert.NoError(t, result.Shutdown(context.Background()))
+}
+
+func TestGoterminJobField(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+
+	//the job can still be replaced like before
+	replaced := make(chan string, 1)
+	result, err := NewCustomInterval(func(ctx context.Context) { replaced <- "old" }, time.Minute, globalTimeLoc, WithClock(clock))
+	assert.NoError(t, err)
+	result.Job = func(ctx context.Context) { replaced <- "new" }
+	assert.NoError(t, result.Start())
+	assert.Equal(t, "new", <-replaced)
+	assert.NoError(t, result.Shutdown(context.Background()))
+
+	//the job with error can be called through the field as well
+	called := 0
+	result, err = NewCustomIntervalWithError(func(ctx context.Context) error {
+		called++
+		return errors.New("no food")
+	}, time.Minute, globalTimeLoc)
+	assert.NoError(t, err)
+	result.Job(context.Background())
+	assert.Equal(t, 1, called)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -12,13 +13,15 @@ type Gover struct {
 	//input and output contain context here
 	//instead of running interface{} context can also serve as input variable
 	//only have to be cautious not to get mistaken by ambiguous key
+	//the given context belongs to the current attempt, it's derived from the context of Run but expires after JobInterval
+	//the attempt information can be read with AttemptFromContext
 	Job func(context.Context) error
-	//context with its cancel function
-	Context context.Context
-	Cancel  context.CancelFunc
-	//deadline when we are supposed to be stop trying
-	//this is mandatory to prevent the job running uncontrolably
+	//fixed deadline when we are supposed to be stop trying
+	//if it's zero then only the timeout or the context can stop it
 	Deadline time.Time
+	//how long each run can try, set by WithTimeout to prevent the job running uncontrolably
+	//it starts on every run, 0 means no timeout
+	timeout time.Duration
 	//number of maximum retry
 	//if job returns an error then it will keep retrying until this number is exceeded
 	MaxRetry int
@@ -30,132 +33,619 @@ type Gover struct {
 	//specify the retry interval
 	//this is a string that supposed to be parsed into time.Duration
 	//if it fails to parse then the timeout for retry will be set into a very short duration
+	//WithRetryInterval is the validated alternative
 	RetryInterval string
+	//decide the waiting duration before each retry, e.g. ExponentialBackoff(time.Second, time.Minute)
+	//if it's set then RetryInterval is ignored
+	Backoff BackoffStrategy
+	//decide whether the error should be retried and optionally how long to wait before
+	//it's checked after NoRetryConditions and the number of retry is still limited by MaxRetry
+	RetryPolicy RetryPolicy
 	//specify the timeout for each jobs
+	//it's ignored if the attempt timeout is set by WithAttemptTimeout
 	JobInterval string
+	//observe the attempts, retries and the outcome of every run, set by WithHooks
+	Hooks Hooks
+	//reject the attempts with ErrCircuitOpen while the downstream is failing, set by WithCircuitBreaker
+	//nil means every attempt is allowed
+	CircuitBreaker *CircuitBreaker
+	//limit the retries that are shared with the other govers, set by WithRetryBudget
+	//the run stops with ErrRetryBudgetExhausted once it's spent, nil means no limit
+	RetryBudget *RetryBudget
+	//limit the attempts that run at once together with the other govers, set by WithBulkhead
+	//the run stops with ErrBulkheadFull if the attempt is rejected, nil means no limit
+	Bulkhead *Bulkhead
+	//keep the attempts under the rate that's shared with the other govers, set by WithRateLimiter
+	//every attempt waits for its token, the run stops with ErrDeadline if it can't get one before the deadline
+	RateLimiter *RateLimiter
+	//start another attempt if the running ones are not finished within this delay (hedged requests), set by WithHedging
+	//the first successful attempt is taken and the others are cancelled through their contexts
+	//0 means the attempts run one after another
+	HedgeDelay time.Duration
+	//maximum number of the attempts that can run at once while hedging
+	MaxParallel int
+	//the validated timeout for each attempt, 0 means JobInterval is used
+	attemptTimeout time.Duration
+	//source of the time for deadline, timeouts and retry interval
+	clock Clock
+	//receives the recovered panics of the job
+	//the panic is also treated as the job error, so it can be retried
+	panicHandler func(error)
+	//key of the gotermin in crontab if it's used to retry a scheduled job
+	key string
+	//whether the run returns only once all of its jobs do, even if it's cancelled
+	//the scheduler needs it, so the shutdown and the overlap policy see the jobs that are still running
+	waitForJobs bool
 }
 
-func New(timeout time.Duration, job func(context.Context) error) (*Gover, error) {
-	//timeout can't be lower than 1ns
-	if timeout.Nanoseconds() == int64(0) {
-		return nil, fmt.Errorf("Timeout %.0fs is too short", timeout.Seconds())
+//create the gover for the job, configured by the options
+//e.g. New(job, WithTimeout(time.Minute), WithMaxRetry(3), WithRetryInterval(time.Second))
+//return error if the job is empty or any option is not valid
+func New(job func(context.Context) error, opts ...Option) (*Gover, error) {
+	if job == nil {
+		return nil, fmt.Errorf("Please input a valid job")
 	}
 
-	return &Gover{
-		Context:  context.Background(),
-		Job:      job,
-		Deadline: time.Now().Add(timeout),
-	}, nil
+	cfg, err := newConfig(targetGover, opts)
+	if err != nil {
+		return nil, err
+	}
 
+	return newGover(job, cfg), nil
 }
 
-func (g *Gover) Run() error {
-	//return immediately if deadline is already exceeded
-	if g.Deadline.Before(time.Now()) {
-		return fmt.Errorf("Deadline %+v is already exceeded", g.Deadline)
+//create the gover with the validated options
+func newGover(job func(context.Context) error, cfg config) *Gover {
+	g := &Gover{
+		Job:            job,
+		timeout:        cfg.timeout,
+		attemptTimeout: cfg.attemptTimeout,
+		clock:          cfg.clock,
+		panicHandler:   cfg.panicHandler,
+		Hooks:          cfg.hooks,
+		CircuitBreaker: cfg.circuitBreaker,
+		RetryBudget:    cfg.retryBudget,
+		Bulkhead:       cfg.bulkhead,
+		RateLimiter:    cfg.rateLimiter,
+		HedgeDelay:     cfg.hedgeDelay,
+		MaxParallel:    cfg.maxParallel,
+	}
+	if cfg.retry != nil {
+		cfg.retry.apply(g)
+	}
+	return g
+}
+
+//set how long gover can try from the start, including the retries and the waiting between them
+//without it only the context can stop the retries
+//the schedulers don't accept it, the retries of their runs last until the next one is due
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *config) error {
+		if err := c.only(targetGover, "WithTimeout"); err != nil {
+			return err
+		}
+		if timeout <= 0 {
+			return fmt.Errorf("Timeout %s is too short", timeout)
+		}
+		c.timeout = timeout
+		return nil
+	}
+}
+
+//set how long a single attempt can last, the context of the job expires afterwards
+//without it each attempt can last until the whole run is done
+func WithAttemptTimeout(timeout time.Duration) Option {
+	return func(c *config) error {
+		if timeout <= 0 {
+			return fmt.Errorf("Attempt timeout %s is too short", timeout)
+		}
+		c.attemptTimeout = timeout
+		c.retried("WithAttemptTimeout")
+		return nil
+	}
+}
+
+//set how many times the job will be done again if error is returned
+func WithMaxRetry(maxRetry int) Option {
+	return func(c *config) error {
+		if maxRetry < 0 {
+			return fmt.Errorf("Invalid maximum number of retry: %d", maxRetry)
+		}
+		c.retryConfig().MaxRetry = maxRetry
+		return nil
+	}
+}
+
+//set the waiting duration between the retries
+//it's the same as WithBackoff(ConstantBackoff(interval))
+func WithRetryInterval(interval time.Duration) Option {
+	return func(c *config) error {
+		if interval < 0 {
+			return fmt.Errorf("Invalid retry interval: %s", interval)
+		}
+		c.retryConfig().Backoff = ConstantBackoff(interval)
+		return nil
+	}
+}
+
+//set the strategy that decides the waiting duration before each retry
+func WithBackoff(backoff BackoffStrategy) Option {
+	return func(c *config) error {
+		if backoff == nil {
+			return fmt.Errorf("Please input a valid backoff strategy")
+		}
+		c.retryConfig().Backoff = backoff
+		return nil
+	}
+}
+
+//set the policy that decides whether the error should be retried
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *config) error {
+		if policy == nil {
+			return fmt.Errorf("Please input a valid retry policy")
+		}
+		c.retryConfig().RetryPolicy = policy
+		return nil
 	}
+}
+
+//start another attempt alongside the running ones if they are not finished within the delay
+//up to maxParallel attempts can run at once, the first successful one is taken and the others are cancelled
+//the hedged attempts don't count as retries, the retries only start once all running attempts are failed
+func WithHedging(delay time.Duration, maxParallel int) Option {
+	return func(c *config) error {
+		if delay <= 0 {
+			return fmt.Errorf("Hedging delay %s is too short", delay)
+		}
+		if maxParallel < 2 {
+			return fmt.Errorf("Invalid maximum number of parallel attempts: %d", maxParallel)
+		}
+		c.hedgeDelay = delay
+		c.retried("WithHedging")
+		c.maxParallel = maxParallel
+		return nil
+	}
+}
+
+//set the keywords for error message that's not supposed to be retried
+func WithNoRetryConditions(conditions ...string) Option {
+	return func(c *config) error {
+		c.retryConfig().NoRetryConditions = conditions
+		return nil
+	}
+}
 
+//run the job until it's successful or it stops retrying
+//the context is given to the job, once it's cancelled the run stops immediately (even while waiting for the retry)
+//the running job is cancelled as well, but the run doesn't wait for it to return
+//the gover can be run many times, even concurrently, as long as its fields are not changed meanwhile
+func (g *Gover) Run(ctx context.Context) error {
 	//check the context
 	//if it's not defined then simply use context.Background()
-	if g.Context == nil {
-		g.Context = context.Background()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	//the clock might be empty if the gover is not created by New
+	clock := g.clock
+	if clock == nil {
+		clock = realClock{}
+	}
+
+	//return immediately if deadline is already exceeded
+	now := clock.Now()
+	if !g.Deadline.IsZero() && g.Deadline.Before(now) {
+		err := &RetryError{Reason: ErrDeadline}
+		g.Hooks.giveUp(err)
+		return err
+	}
+
+	//the timeout starts on every run, but it can't exceed the fixed deadline
+	deadline := g.Deadline
+	if g.timeout > 0 && (deadline.IsZero() || now.Add(g.timeout).Before(deadline)) {
+		deadline = now.Add(g.timeout)
 	}
 
-	//set deadline
-	g.Context, g.Cancel = context.WithDeadline(g.Context, g.Deadline)
+	//set deadline if there's any
+	var cancel context.CancelFunc
+	if deadline.IsZero() {
+		ctx, cancel = context.WithCancel(ctx)
+	} else {
+		ctx, cancel = withClockDeadline(ctx, clock, deadline)
+	}
+	defer cancel()
 
-	return g.runWithTimeout()
+	return g.runWithTimeout(ctx, clock)
 }
 
-func (g *Gover) runWithTimeout() error {
-	var currentRetry int
-	var err error
+func (g *Gover) runWithTimeout(ctx context.Context, clock Clock) error {
+	var retryNum int
+	var started int
+	var previousDelay time.Duration
+	var attempts []FailedAttempt
 
-	retryChan := make(chan int, 1)
-	errorChan := make(chan error, 1)
+	//the attempts that are still running by their number
+	//without hedging there's only one at a time
+	running := map[int]*runningAttempt{}
+	maxParallel := 1
+	if g.HedgeDelay > 0 && g.MaxParallel > 1 {
+		maxParallel = g.MaxParallel
+	}
 
-	doTheJob := func(retryNum int, child context.Context) {
-		err = g.Job(g.Context)
+	//every attempt sends only one outcome, so the ones that are not received anymore never block
+	outcomeChan := make(chan attemptOutcome, maxParallel)
+	//the jobs that haven't returned yet, see waitForJobs
+	var jobs sync.WaitGroup
 
-		//check whether the child context is already done or not
-		//if it is error then do nothing, just return
+	doTheJob := func(number, retryNum int, child context.Context) {
+		defer jobs.Done()
+
+		//the job might not respect its context, so it's only timed out once it returns
+		//until then neither the outcome is sent nor the next attempt is started
+		err := callSafely(g.key, func() error { return g.Job(child) })
+		if g.Bulkhead != nil {
+			g.Bulkhead.release()
+		}
+		if _, ok := err.(*PanicError); ok && g.panicHandler != nil {
+			g.panicHandler(err)
+		}
+
+		//the attempt is timed out (or cancelled) if the child context is already done
 		if child.Err() != nil {
+			outcomeChan <- attemptOutcome{number: number, err: child.Err(), timedOut: true}
 			return
 		}
 
-		if err != nil {
-			//if error then this might should be retried
-			//first check whether the error code is in no retry list
-			for _, con := range g.NoRetryConditions {
-				if strings.Contains(err.Error(), con) {
-					errorChan <- fmt.Errorf("Error contains keyword: %s", con)
-					return
-				}
-			}
+		//if there's no error simply send the empty outcome
+		//otherwise decide whether it should be retried
+		if err == nil {
+			outcomeChan <- attemptOutcome{number: number}
+			return
+		}
+		reason, delay := g.checkRetry(retryNum, err)
+		outcomeChan <- attemptOutcome{number: number, err: err, reason: reason, delay: delay}
+	}
 
-			//then check if the retry number already exceeded
-			//if that's the case then just return
-			if retryNum >= g.MaxRetry {
-				errorChan <- fmt.Errorf("Maximum number of retry exceeded")
-				return
-			}
+	//stop retrying and return all failed attempts
+	giveUp := func(reason error) error {
+		err := &RetryError{Reason: reason, Attempts: attempts}
+		g.Hooks.giveUp(err)
+		return err
+	}
 
-			//otherwise fill the retry channel
-			retryChan <- retryNum
-			return
+	//record the failed attempt
+	failed := func(attempt Attempt, err error, startedAt time.Time) {
+		attempts = append(attempts, FailedAttempt{Err: err, Duration: clock.Now().Sub(startedAt)})
+		g.Hooks.attemptError(attempt, err)
+	}
+
+	//the deadline of the whole run or the cancellation of the context
+	parentDone := func() error {
+		if ctx.Err() == context.DeadlineExceeded {
+			return giveUp(ErrDeadline)
 		}
-		//if there's no error simply fill the error channel with nil
-		errorChan <- nil
+		return giveUp(ctx.Err())
 	}
 
-	//do the job until it's done or expired
-	for {
-		//set retry interval here
-		//default value is 1ms only, change only if parsing duration returns no error
-		retryInterval := time.Millisecond
-		if rt, err := time.ParseDuration(g.RetryInterval); err == nil {
-			retryInterval = rt
+	//the run is done before the running attempts, all of them are failed
+	abandon := func() error {
+		for number := 1; number <= started; number++ {
+			if r, ok := running[number]; ok {
+				failed(r.attempt, ctx.Err(), r.startedAt)
+			}
+		}
+		return parentDone()
+	}
+
+	//cancel the attempts that are still running once the run is done, e.g. the losers of the hedged attempts
+	//the run returns right away, the jobs that ignore the cancellation are left behind
+	defer func() {
+		for number, r := range running {
+			r.cancel()
+			g.releaseBreaker(r.generation)
+			delete(running, number)
+		}
+		if g.waitForJobs {
+			jobs.Wait()
+		}
+	}()
+
+	//start the next attempt unless the context is done, the circuit breaker, the bulkhead or the rate limiter doesn't allow it
+	//a hedged attempt is simply skipped in that case (it doesn't wait for the bulkhead or the rate limiter)
+	//otherwise the run stops with the error
+	launch := func(hedged bool) error {
+		reject := func(err error) error {
+			switch {
+			case hedged:
+				return nil
+			case ctx.Err() != nil:
+				return parentDone()
+			case err == context.DeadlineExceeded:
+				return giveUp(ErrDeadline)
+			}
+			return giveUp(err)
+		}
+		if ctx.Err() != nil {
+			return reject(ctx.Err())
+		}
+
+		var generation uint64
+		if g.CircuitBreaker != nil {
+			var err error
+			if generation, err = g.CircuitBreaker.allow(); err != nil {
+				return reject(err)
+			}
+		}
+		if g.Bulkhead != nil {
+			if err := g.Bulkhead.acquire(ctx, !hedged); err != nil {
+				g.releaseBreaker(generation)
+				return reject(err)
+			}
+		}
+		//the rate limiter is the last one, so the job starts right after getting its token
+		if g.RateLimiter != nil {
+			if err := g.RateLimiter.wait(ctx, !hedged); err != nil {
+				g.releaseBreaker(generation)
+				if g.Bulkhead != nil {
+					g.Bulkhead.release()
+				}
+				return reject(err)
+			}
 		}
 
 		//create child context
 		//if jobinterval is stated then use different interval
 		//otherwise derivate it from the parent
 		var childCtx context.Context
-		if jobInterval, err := time.ParseDuration(g.JobInterval); err != nil {
-			childCtx, _ = context.WithCancel(g.Context)
+		var childCancel context.CancelFunc
+		if jobInterval := g.jobTimeout(); jobInterval <= 0 {
+			childCtx, childCancel = context.WithCancel(ctx)
 		} else {
-			childCtx, _ = context.WithTimeout(g.Context, jobInterval)
+			childCtx, childCancel = withClockDeadline(ctx, clock, clock.Now().Add(jobInterval))
+		}
+
+		//the job can find out which attempt it is from the child context
+		started += 1
+		attempt := Attempt{Number: started}
+		attempt.Deadline, _ = childCtx.Deadline()
+		if len(attempts) > 0 {
+			attempt.PreviousErr = attempts[len(attempts)-1].Err
 		}
+		childCtx = withAttempt(childCtx, attempt)
+
+		g.Hooks.attemptStart(attempt)
+		running[started] = &runningAttempt{attempt: attempt, cancel: childCancel, startedAt: clock.Now(), generation: generation}
+		jobs.Add(1)
+		go doTheJob(started, retryNum, childCtx)
+		return nil
+	}
 
-		go doTheJob(currentRetry, childCtx)
+	//start another attempt if the running ones are not finished within the hedging delay
+	var hedgeTimer Timer
+	var hedgeChan <-chan time.Time
+	stopHedging := func() {
+		if hedgeTimer != nil {
+			hedgeTimer.Stop()
+			hedgeTimer, hedgeChan = nil, nil
+		}
+	}
+	startHedging := func() {
+		stopHedging()
+		if len(running) > 0 && len(running) < maxParallel {
+			hedgeTimer = clock.NewTimer(g.HedgeDelay)
+			hedgeChan = hedgeTimer.C()
+		}
+	}
+	defer stopHedging()
+
+	//sleep before the retry with the given number after the failed attempt
+	//the requested delay is used if it's given, otherwise the regular one
+	//return the error if the retry budget is spent or the context is done meanwhile
+	waitForRetry := func(retryNum int, requested time.Duration, attempt Attempt, err error) error {
+		if g.RetryBudget != nil && !g.RetryBudget.withdraw() {
+			return giveUp(ErrRetryBudgetExhausted)
+		}
+
+		delay := requested
+		if delay <= 0 {
+			delay = g.retryDelay(retryNum, previousDelay)
+		}
+		previousDelay = delay
+		g.Hooks.retry(attempt, err, delay)
+
+		timer := clock.NewTimer(delay)
 		select {
-		case <-g.Context.Done():
+		case <-timer.C():
+			return nil
+		case <-ctx.Done():
+			timer.Stop()
+			return parentDone()
+		}
+	}
+
+	//do the job until it's done or expired
+	if err := launch(false); err != nil {
+		return err
+	}
+	startHedging()
+	for {
+		select {
+		case <-ctx.Done():
 			//in this case the context is already cancelled
-			//return error immediately and abandon the currently running go routine
-			return g.Context.Err()
-		case err := <-errorChan:
-			//return whatever error there is in this channel
-			return err
-		case retryNum := <-retryChan:
-			//in this case continue the loop
-			//update the currentRetry with 1 + retryNum
-			currentRetry = retryNum + 1
-
-			//sleep for the set interval before retrying
-			time.Sleep(retryInterval)
-			continue
-		case <-childCtx.Done():
-			//in this case child is timed out
-			//return error if max retry is exceeded
-			if currentRetry >= g.MaxRetry {
-				return fmt.Errorf("Maximum number of retry exceeded")
+			//return error immediately and abandon the currently running go routines
+			return abandon()
+		case <-hedgeChan:
+			//the running attempts are too slow, start another one alongside them
+			hedgeTimer, hedgeChan = nil, nil
+			if err := launch(true); err != nil {
+				return err
+			}
+			startHedging()
+		case outcome := <-outcomeChan:
+			r, ok := running[outcome.number]
+			if !ok {
+				continue
+			}
+			delete(running, outcome.number)
+			r.cancel()
+
+			//the first successful attempt wins, the others are cancelled
+			if outcome.err == nil {
+				g.recordBreaker(r.generation, true)
+				if g.RetryBudget != nil {
+					g.RetryBudget.deposit()
+				}
+				g.Hooks.success(r.attempt)
+				return nil
+			}
+
+			failed(r.attempt, outcome.err, r.startedAt)
+			reason := outcome.reason
+			if outcome.timedOut {
+				//in this case child is timed out or the parent is done
+				if ctx.Err() != nil {
+					g.releaseBreaker(r.generation)
+					return abandon()
+				}
+				//return error if max retry is exceeded
+				if retryNum >= g.MaxRetry {
+					reason = ErrMaxRetry
+				}
+			}
+			g.recordBreaker(r.generation, false)
+
+			//the other hedged attempts might still be successful
+			//so only the error that's not retryable stops them
+			if len(running) > 0 {
+				if reason != nil && reason != ErrMaxRetry {
+					return giveUp(reason)
+				}
+				if hedgeTimer == nil {
+					startHedging()
+				}
+				continue
 			}
-			//otherwise retry
-			currentRetry += 1
-			time.Sleep(retryInterval)
-			continue
+
+			//return the error if it should not be retried
+			if reason != nil {
+				return giveUp(reason)
+			}
+
+			//otherwise sleep for the set interval before retrying
+			stopHedging()
+			retryNum += 1
+			if err := waitForRetry(retryNum, outcome.delay, r.attempt, outcome.err); err != nil {
+				return err
+			}
+			if err := launch(false); err != nil {
+				return err
+			}
+			startHedging()
 		}
 	}
+}
+
+//an attempt that's still running
+type runningAttempt struct {
+	attempt Attempt
+	//cancel the context of the attempt
+	cancel context.CancelFunc
+	//when the attempt is started
+	startedAt time.Time
+	//generation of the circuit breaker when the attempt is allowed
+	generation uint64
+}
+
+//the outcome of a single attempt
+type attemptOutcome struct {
+	//number of the attempt, starting from 1
+	number int
+	//the error returned by the job, nil if it's successful
+	//the error of the child context if it's timed out
+	err error
+	//whether the child context is done before the job is finished
+	timedOut bool
+	//why it should not be retried, nil if it should
+	reason error
+	//how long to wait before the next one, 0 means the regular retry delay
+	delay time.Duration
+}
 
-	return fmt.Errorf("Unknown error")
+//decide whether the error of the retry with the given number (starting from 0) should be retried
+//return the reason if it should not, otherwise the delay requested for the next one
+func (g *Gover) checkRetry(retryNum int, err error) (error, time.Duration) {
+	//the permanent error is never retried
+	if IsPermanent(err) {
+		return ErrNonRetryable, 0
+	}
+
+	//check whether the error code is in no retry list
+	for _, con := range g.NoRetryConditions {
+		if strings.Contains(err.Error(), con) {
+			return fmt.Errorf("%w, it contains keyword: %s", ErrNonRetryable, con), 0
+		}
+	}
+
+	//let the retry policy decide, the attempt number starts from 1
+	var delay time.Duration
+	if g.RetryPolicy != nil {
+		var retry bool
+		if retry, delay = g.RetryPolicy(retryNum+1, err); !retry {
+			return ErrNonRetryable, 0
+		}
+	}
+
+	//then check if the retry number already exceeded
+	if retryNum >= g.MaxRetry {
+		return ErrMaxRetry, 0
+	}
+
+	//the delay requested by the job has the highest priority
+	if requested, ok := retryAfter(err); ok {
+		delay = requested
+	}
+	return nil, delay
+}
+
+//how long each attempt can last, 0 means no timeout
+//use the attempt timeout if it's set, otherwise the job interval
+func (g *Gover) jobTimeout() time.Duration {
+	if g.attemptTimeout > 0 {
+		return g.attemptTimeout
+	}
+	if jobInterval, err := time.ParseDuration(g.JobInterval); err == nil {
+		return jobInterval
+	}
+	return 0
+}
+
+//how long to wait before the retry with the given number
+//use the backoff strategy if there's any, otherwise the retry interval
+func (g *Gover) retryDelay(retryNum int, previous time.Duration) time.Duration {
+	if g.Backoff != nil {
+		if delay := g.Backoff(retryNum, previous); delay > 0 {
+			return delay
+		}
+		return 0
+	}
+
+	//default value is 1ms only, change only if parsing duration returns no error
+	if rt, err := time.ParseDuration(g.RetryInterval); err == nil {
+		return rt
+	}
+	return time.Millisecond
+}
+
+//report the result of the attempt to the circuit breaker if there's any
+func (g *Gover) recordBreaker(generation uint64, success bool) {
+	if g.CircuitBreaker != nil {
+		g.CircuitBreaker.record(generation, success)
+	}
+}
+
+//give back the attempt to the circuit breaker if the run is done before it's finished
+func (g *Gover) releaseBreaker(generation uint64) {
+	if g.CircuitBreaker != nil {
+		g.CircuitBreaker.release(generation)
+	}
 }
@@ -2,8 +2,11 @@ package gover
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/stretchr/testify/assert"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -26,17 +29,16 @@ func TestGover(t *testing.T) {
 	var cat Animal
 
 	var timeNull time.Duration
-	_, err := New(timeNull, cat.setName)
+	_, err := New(cat.setName, WithTimeout(timeNull))
 	assert.Error(t, err)
 
-	gover, err := New(time.Second*5, cat.setName)
+	gover, err := New(cat.setName, WithTimeout(time.Second*5))
 	assert.NoError(t, err)
-	err = gover.Run()
+	err = gover.Run(context.Background())
 	assert.Error(t, err)
 	assert.Equal(t, "", cat.Name)
 
-	gover.Context = ctx
-	err = gover.Run()
+	err = gover.Run(ctx)
 	assert.NoError(t, err)
 	assert.Equal(t, "Mr. Meowingston", cat.Name)
 }
@@ -52,15 +54,15 @@ func TestRetryFunctionality(t *testing.T) {
 		return nil
 	}
 
-	gover, err := New(time.Hour, testFunc)
+	gover, err := New(testFunc, WithTimeout(time.Hour))
 	assert.NoError(t, err)
 	gover.MaxRetry = 2
-	err = gover.Run()
+	err = gover.Run(context.Background())
 	assert.Error(t, err)
 
 	initNum = 0
 	gover.MaxRetry = 3
-	err = gover.Run()
+	err = gover.Run(context.Background())
 	assert.NoError(t, err)
 
 	//test the retry interval
@@ -68,7 +70,7 @@ func TestRetryFunctionality(t *testing.T) {
 	gover.MaxRetry = 3
 	gover.RetryInterval = "100ms"
 	timeNow := time.Now()
-	err = gover.Run()
+	err = gover.Run(context.Background())
 	elapsedTime := time.Since(timeNow).Seconds()
 	assert.NoError(t, err)
 
@@ -83,7 +85,7 @@ func TestRetryFunctionality(t *testing.T) {
 	initNum = 0
 	gover.RetryInterval = "100zs"
 	timeNow = time.Now()
-	err = gover.Run()
+	err = gover.Run(context.Background())
 	elapsedTime = time.Since(timeNow).Seconds()
 	assert.NoError(t, err)
 
@@ -95,7 +97,7 @@ func TestRetryFunctionality(t *testing.T) {
 	initNum = 0
 	gover.MaxRetry = 3
 	gover.NoRetryConditions = []string{"should"}
-	err = gover.Run()
+	err = gover.Run(context.Background())
 	assert.Error(t, err)
 
 	//test job interval
@@ -111,14 +113,14 @@ func TestRetryFunctionality(t *testing.T) {
 		return err
 	}
 
-	gover, err = New(time.Hour, timeoutFunc)
+	gover, err = New(timeoutFunc, WithTimeout(time.Hour))
 	assert.NoError(t, err)
 	gover.MaxRetry = 5
 	//set job interval into only 300ms
 	//so the first (520ms), second(420ms) and third(320ms) should fail
 	//we expect it to succed on the 4th trial
 	gover.JobInterval = "300ms"
-	err = gover.Run()
+	err = gover.Run(context.Background())
 	assert.NoError(t, err)
 	assert.Equal(t, 4, tryNum)
 
@@ -126,17 +128,374 @@ func TestRetryFunctionality(t *testing.T) {
 	initialTO = 520
 	tryNum = 0
 	gover.JobInterval = "1ns" //this is practically impossible to pass
-	err = gover.Run()
+	err = gover.Run(context.Background())
 	assert.Error(t, err)
 	assert.Equal(t, gover.MaxRetry, tryNum-1)
 
 	//now test collision between job interval and the parent context timeout
-	gover, _ = New(time.Second*1000, timeoutFunc)
+	gover, _ = New(timeoutFunc, WithTimeout(time.Second*1000))
 	initialTO = 520
 	tryNum = 0
 	gover.JobInterval = "300ms"
 	//previously it returns 4, now it should be less and returns an error
-	err = gover.Run()
+	err = gover.Run(context.Background())
 	assert.Error(t, err)
 	assert.Equal(t, 1, tryNum)
 }
+
+func TestGoverWithFakeClock(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	start := clock.Now()
+
+	tryNum := 0
+	job := func(ctx context.Context) error {
+		tryNum += 1
+		if tryNum < 3 {
+			return fmt.Errorf("not yet")
+		}
+		return nil
+	}
+
+	_, err := New(job, WithTimeout(time.Hour), WithClock(nil))
+	assert.Error(t, err)
+
+	gover, err := New(job, WithTimeout(time.Hour*24), WithClock(clock))
+	assert.NoError(t, err)
+	gover.MaxRetry = 3
+	gover.RetryInterval = "1h"
+
+	result := make(chan error, 1)
+	go func() { result <- gover.Run(context.Background()) }()
+
+	//the deadline and the retry interval are waiting
+	for i := 0; i < 2; i++ {
+		clock.BlockUntil(2)
+		clock.Advance(time.Hour)
+	}
+	assert.NoError(t, <-result)
+	assert.Equal(t, 3, tryNum)
+	assert.Equal(t, start.Add(time.Hour*2), clock.Now())
+
+	//fixed deadline already exceeded according to the clock
+	gover.Deadline = start.Add(time.Hour * 24)
+	clock.Advance(time.Hour * 24)
+	assert.Error(t, gover.Run(context.Background()))
+}
+
+func TestGoverRetryError(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+
+	tryNum := 0
+	job := func(ctx context.Context) error {
+		tryNum += 1
+		return fmt.Errorf("try %d", tryNum)
+	}
+
+	gover, _ := New(job, WithTimeout(time.Hour), WithClock(clock))
+	gover.MaxRetry = 2
+	gover.Backoff = ConstantBackoff(0)
+	err := gover.Run(context.Background())
+
+	//all attempts are listed and the last one is wrapped
+	var retryErr *RetryError
+	assert.Equal(t, true, errors.As(err, &retryErr))
+	assert.Equal(t, true, errors.Is(err, ErrMaxRetry))
+	assert.Equal(t, false, errors.Is(err, ErrDeadline))
+	assert.Equal(t, 3, len(retryErr.Attempts))
+	for i, attempt := range retryErr.Attempts {
+		assert.Equal(t, fmt.Sprintf("try %d", i+1), attempt.Err.Error())
+	}
+	assert.Equal(t, "try 3", errors.Unwrap(err).Error())
+	assert.Equal(t, "Maximum number of retry exceeded (attempts: 3): try 3", err.Error())
+
+	//the job is still running on the deadline
+	started := make(chan struct{})
+	blocking := func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	gover, _ = New(blocking, WithTimeout(time.Hour), WithClock(clock))
+	result := make(chan error, 1)
+	go func() { result <- gover.Run(context.Background()) }()
+	<-started
+	clock.Advance(time.Hour)
+
+	err = <-result
+	assert.Equal(t, true, errors.As(err, &retryErr))
+	assert.Equal(t, true, errors.Is(err, ErrDeadline))
+	assert.Equal(t, true, errors.Is(err, context.DeadlineExceeded))
+	assert.Equal(t, []FailedAttempt{{Err: context.DeadlineExceeded, Duration: time.Hour}}, retryErr.Attempts)
+
+	//the fixed deadline is already exceeded before the first attempt
+	gover, _ = New(job, WithTimeout(time.Hour), WithClock(clock))
+	gover.Deadline = clock.Now()
+	clock.Advance(time.Hour)
+	assert.Equal(t, true, errors.Is(gover.Run(context.Background()), ErrDeadline))
+}
+
+func TestGoverOptions(t *testing.T) {
+	job := func(ctx context.Context) error { return nil }
+
+	invalids := []Option{
+		WithTimeout(0),
+		WithAttemptTimeout(-time.Second),
+		WithMaxRetry(-1),
+		WithRetryInterval(-time.Second),
+		WithBackoff(nil),
+		WithRetryPolicy(nil),
+	}
+	for _, opt := range invalids {
+		_, err := New(job, opt)
+		assert.Error(t, err)
+	}
+	_, err := New(nil)
+	assert.Error(t, err)
+
+	//the options of the schedulers can't be used
+	_, err = New(job, WithOverlapPolicy(OverlapSkip))
+	assert.Equal(t, "WithOverlapPolicy can't be used by gover", err.Error())
+	_, err = Do(context.Background(), func(ctx context.Context) (int, error) { return 1, nil }, WithDSTPolicy(DSTPolicy{}))
+	assert.Equal(t, "WithDSTPolicy can't be used by gover", err.Error())
+
+	//and the timeout of gover can't be used by the schedulers
+	_, err = NewDaily(randomFunc, "0230", globalTimeLoc, WithTimeout(time.Hour))
+	assert.Equal(t, "WithTimeout can't be used by the scheduler", err.Error())
+	_, err = NewCrontab(globalTimeLoc, WithTimeout(time.Hour))
+	assert.Error(t, err)
+	crontab, _ := NewCrontab(globalTimeLoc)
+	assert.Error(t, crontab.RegisterNewHourly("foo", randomFunc, "30", WithTimeout(time.Hour)))
+
+	//without timeout there's no deadline
+	gover, err := New(job)
+	assert.NoError(t, err)
+	assert.Equal(t, true, gover.Deadline.IsZero())
+	assert.NoError(t, gover.Run(context.Background()))
+
+	gover, err = New(job, WithTimeout(time.Hour), WithMaxRetry(3), WithRetryInterval(time.Minute),
+		WithAttemptTimeout(time.Second), WithNoRetryConditions("foo"))
+	assert.NoError(t, err)
+	assert.Equal(t, time.Hour, gover.timeout)
+	assert.Equal(t, 3, gover.MaxRetry)
+	assert.Equal(t, []string{"foo"}, gover.NoRetryConditions)
+	assert.Equal(t, time.Minute, gover.retryDelay(1, 0))
+	assert.Equal(t, time.Second, gover.jobTimeout())
+
+	//the later option wins
+	gover, _ = New(job, WithRetryInterval(time.Minute), WithBackoff(ExponentialBackoff(time.Second, time.Hour)))
+	assert.Equal(t, time.Second*4, gover.retryDelay(3, 0))
+}
+
+func TestGoverAttemptTimeout(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+
+	//the first attempt never finishes by itself
+	tryNum := 0
+	started := make(chan struct{}, 1)
+	job := func(ctx context.Context) error {
+		tryNum += 1
+		if tryNum == 1 {
+			started <- struct{}{}
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		return nil
+	}
+
+	gover, _ := New(job, WithClock(clock), WithMaxRetry(1), WithAttemptTimeout(time.Second), WithRetryInterval(0))
+	result := make(chan error, 1)
+	go func() { result <- gover.Run(context.Background()) }()
+
+	<-started
+	clock.Advance(time.Second)
+	assert.NoError(t, <-result)
+	assert.Equal(t, 2, tryNum)
+}
+
+func TestGoverReusable(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+
+	//the job waits until its context is done
+	started := make(chan struct{}, 1)
+	blocking := func(ctx context.Context) error {
+		started <- struct{}{}
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	//the timeout starts on every run, even if the gover is created much earlier
+	gover, _ := New(blocking, WithTimeout(time.Hour), WithClock(clock))
+	for i := 0; i < 2; i++ {
+		clock.Advance(time.Hour * 2)
+		result := make(chan error, 1)
+		go func() { result <- gover.Run(context.Background()) }()
+
+		<-started
+		clock.Advance(time.Minute * 59)
+		assert.Equal(t, 0, len(result))
+		clock.Advance(time.Minute)
+		assert.Equal(t, true, errors.Is(<-result, ErrDeadline))
+	}
+}
+
+func TestGoverConcurrentRun(t *testing.T) {
+	//every run fails on its first attempt and succeeds on the second one
+	var runs int64
+	job := func(ctx context.Context) error {
+		attempt, _ := AttemptFromContext(ctx)
+		if attempt.Number == 1 {
+			return fmt.Errorf("not yet")
+		}
+		atomic.AddInt64(&runs, 1)
+		return nil
+	}
+
+	gover, _ := New(job, WithTimeout(time.Minute), WithMaxRetry(1), WithRetryInterval(time.Millisecond))
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, gover.Run(context.Background()))
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int64(20), atomic.LoadInt64(&runs))
+}
+
+func TestGoverCancel(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+
+	tryNum := 0
+	job := func(ctx context.Context) error {
+		tryNum++
+		return fmt.Errorf("try %d", tryNum)
+	}
+
+	//the cancellation stops the waiting for the next retry
+	gover, _ := New(job, WithClock(clock), WithMaxRetry(5), WithRetryInterval(time.Hour))
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error, 1)
+	go func() { result <- gover.Run(ctx) }()
+
+	clock.BlockUntil(1)
+	cancel()
+	err := <-result
+	assert.Equal(t, true, errors.Is(err, context.Canceled))
+	var retryErr *RetryError
+	assert.Equal(t, true, errors.As(err, &retryErr))
+	assert.Equal(t, 1, len(retryErr.Attempts))
+	assert.Equal(t, "try 1", retryErr.Attempts[0].Err.Error())
+	assert.Equal(t, 1, tryNum)
+
+	//the timeout as well
+	tryNum = 0
+	gover, _ = New(job, WithClock(clock), WithTimeout(time.Minute), WithMaxRetry(5), WithRetryInterval(time.Hour))
+	go func() { result <- gover.Run(context.Background()) }()
+
+	clock.BlockUntil(2)
+	clock.Advance(time.Minute)
+	err = <-result
+	assert.Equal(t, true, errors.Is(err, ErrDeadline))
+	assert.Equal(t, true, errors.Is(err, context.DeadlineExceeded))
+	assert.Equal(t, 1, tryNum)
+
+	//a cancelled context doesn't run the job at all
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	tryNum = 0
+	assert.Equal(t, true, errors.Is(gover.Run(ctx), context.Canceled))
+	assert.Equal(t, 0, tryNum)
+}
+
+func TestGoverIgnoredContext(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+
+	//the job ignores its context, the run still returns right away
+	release := make(chan struct{})
+	defer close(release)
+	started := make(chan struct{}, 2)
+	job := func(ctx context.Context) error {
+		started <- struct{}{}
+		<-release
+		return nil
+	}
+
+	gover, _ := New(job, WithClock(clock), WithTimeout(time.Minute))
+	result := make(chan error, 1)
+	go func() { result <- gover.Run(context.Background()) }()
+	<-started
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	err := <-result
+	assert.Equal(t, true, errors.Is(err, ErrDeadline))
+	var retryErr *RetryError
+	assert.Equal(t, true, errors.As(err, &retryErr))
+	assert.Equal(t, 1, len(retryErr.Attempts))
+
+	//the cancellation as well
+	gover, _ = New(job)
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() { result <- gover.Run(ctx) }()
+	<-started
+	cancel()
+	assert.Equal(t, true, errors.Is(<-result, context.Canceled))
+}
+
+func TestGoverHedging(t *testing.T) {
+	job := func(ctx context.Context) error { return nil }
+	_, err := New(job, WithHedging(0, 2))
+	assert.Error(t, err)
+	_, err = New(job, WithHedging(time.Second, 1))
+	assert.Error(t, err)
+
+	//the first attempt is too slow, the hedged one wins and the slow one is cancelled
+	clock := NewFakeClock(time.Now())
+	cancelled := make(chan error, 1)
+	hedged := func(ctx context.Context) error {
+		attempt, _ := AttemptFromContext(ctx)
+		if attempt.Number == 1 {
+			<-ctx.Done()
+			cancelled <- ctx.Err()
+			return ctx.Err()
+		}
+		return nil
+	}
+	gover, err := New(hedged, WithClock(clock), WithHedging(time.Second, 2))
+	assert.NoError(t, err)
+	result := make(chan error, 1)
+	go func() { result <- gover.Run(context.Background()) }()
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Second)
+	assert.NoError(t, <-result)
+	assert.Equal(t, context.Canceled, <-cancelled)
+
+	//no more than the given number of attempts run at once
+	started := make(chan int, 10)
+	blocking := func(ctx context.Context) error {
+		attempt, _ := AttemptFromContext(ctx)
+		started <- attempt.Number
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	gover, _ = New(blocking, WithClock(clock), WithTimeout(time.Minute), WithHedging(time.Second, 3))
+	go func() { result <- gover.Run(context.Background()) }()
+
+	//the deadline of the run and the hedging delay are waiting
+	for i := 1; i <= 3; i++ {
+		assert.Equal(t, i, <-started)
+		if i < 3 {
+			clock.BlockUntil(2)
+			clock.Advance(time.Second)
+		}
+	}
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	err = <-result
+	assert.Equal(t, true, errors.Is(err, ErrDeadline))
+	var retryErr *RetryError
+	assert.Equal(t, true, errors.As(err, &retryErr))
+	assert.Equal(t, 3, len(retryErr.Attempts))
+	assert.Equal(t, 0, len(started))
+}
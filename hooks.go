@@ -0,0 +1,61 @@
+//hooks to observe what gover is doing
+//they can be used to log or meter the attempts and retries without wrapping the job
+package gover
+
+import "time"
+
+//the callbacks of a run, every one of them is optional
+//they are called synchronously by the run, so they should return quickly
+//a run calls them one after another, but concurrent runs call them concurrently
+type Hooks struct {
+	//called right before an attempt starts
+	OnAttemptStart func(attempt Attempt)
+	//called when an attempt fails, times out or is abandoned because the run is done
+	OnAttemptError func(attempt Attempt, err error)
+	//called when a failed attempt is going to be retried, with the delay before the next attempt
+	OnRetry func(attempt Attempt, err error, delay time.Duration)
+	//called when the run stops retrying, with the same error that's returned by Run
+	OnGiveUp func(err *RetryError)
+	//called when an attempt is successful
+	OnSuccess func(attempt Attempt)
+}
+
+//set the hooks that observe every run of gover
+//with a retried scheduled job they observe each retry of the job
+func WithHooks(hooks Hooks) Option {
+	return func(c *config) error {
+		c.hooks = hooks
+		c.retried("WithHooks")
+		return nil
+	}
+}
+
+func (h Hooks) attemptStart(attempt Attempt) {
+	if h.OnAttemptStart != nil {
+		h.OnAttemptStart(attempt)
+	}
+}
+
+func (h Hooks) attemptError(attempt Attempt, err error) {
+	if h.OnAttemptError != nil {
+		h.OnAttemptError(attempt, err)
+	}
+}
+
+func (h Hooks) retry(attempt Attempt, err error, delay time.Duration) {
+	if h.OnRetry != nil {
+		h.OnRetry(attempt, err, delay)
+	}
+}
+
+func (h Hooks) giveUp(err *RetryError) {
+	if h.OnGiveUp != nil {
+		h.OnGiveUp(err)
+	}
+}
+
+func (h Hooks) success(attempt Attempt) {
+	if h.OnSuccess != nil {
+		h.OnSuccess(attempt)
+	}
+}
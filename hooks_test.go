@@ -0,0 +1,60 @@
+package gover
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestGoverHooks(t *testing.T) {
+	var events []string
+	hooks := Hooks{
+		OnAttemptStart: func(attempt Attempt) {
+			events = append(events, fmt.Sprintf("start %d", attempt.Number))
+		},
+		OnAttemptError: func(attempt Attempt, err error) {
+			events = append(events, fmt.Sprintf("error %d: %s", attempt.Number, err))
+		},
+		OnRetry: func(attempt Attempt, err error, delay time.Duration) {
+			events = append(events, fmt.Sprintf("retry %d after %s", attempt.Number, delay))
+		},
+		OnGiveUp: func(err *RetryError) {
+			events = append(events, fmt.Sprintf("give up: %s", err))
+		},
+		OnSuccess: func(attempt Attempt) {
+			events = append(events, fmt.Sprintf("success %d", attempt.Number))
+		},
+	}
+
+	tryNum := 0
+	job := func(ctx context.Context) error {
+		tryNum++
+		if tryNum < 3 {
+			return fmt.Errorf("try %d", tryNum)
+		}
+		return nil
+	}
+
+	gover, _ := New(job, WithHooks(hooks), WithMaxRetry(3), WithBackoff(LinearBackoff(time.Millisecond, time.Millisecond)))
+	assert.NoError(t, gover.Run(context.Background()))
+	assert.Equal(t, []string{
+		"start 1", "error 1: try 1", "retry 1 after 1ms",
+		"start 2", "error 2: try 2", "retry 2 after 2ms",
+		"start 3", "success 3",
+	}, events)
+
+	//the permanent error is not retried
+	events = nil
+	tryNum = 0
+	gover.Job = func(ctx context.Context) error { return Permanent(errors.New("broken")) }
+	err := gover.Run(context.Background())
+	assert.Equal(t, true, errors.Is(err, ErrNonRetryable))
+	assert.Equal(t, []string{"start 1", "error 1: broken", fmt.Sprintf("give up: %s", err)}, events)
+
+	//without hooks nothing happens
+	gover, _ = New(job)
+	assert.Error(t, gover.Run(context.Background()))
+}
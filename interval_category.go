@@ -3,7 +3,6 @@ package gover
 
 import (
 	"fmt"
-	"math"
 	"strconv"
 	"strings"
 	"time"
@@ -12,10 +11,20 @@ import (
 type (
 	interval interface {
 		//interval duration between jobs
+		//this is only informative, the real schedule is decided by Next
 		getInterval() time.Duration
-		//sleep duration is time delay before executing the first job
-		//input is the to be subtracted time (use time now)
-		getSleepDuration(time.Time) (time.Duration, error)
+		//whether the first job should run right away when the scheduler starts
+		//instead of waiting for the first Next
+		runsImmediately() bool
+		//the next wall clock time the job should run, strictly after the input time
+		//zero time means that the schedule will never run again
+		Next(after time.Time) time.Time
+	}
+
+	//schedule that depends on when the scheduler is started
+	startedInterval interface {
+		//the schedule of the scheduler that's started at the given time
+		startingAt(start time.Time) interval
 	}
 )
 
@@ -30,8 +39,11 @@ type customIntervalJob struct {
 }
 
 func (cij customIntervalJob) getInterval() time.Duration { return cij.timeInterval }
-func (cij customIntervalJob) getSleepDuration(startTime time.Time) (time.Duration, error) {
-	return time.Second * 0, nil
+func (cij customIntervalJob) runsImmediately() bool      { return true }
+
+//the next run is simply one interval after the previous one
+func (cij customIntervalJob) Next(after time.Time) time.Time {
+	return after.Add(cij.timeInterval)
 }
 
 func (cij customIntervalJob) String() string {
@@ -49,57 +61,55 @@ func (cij customIntervalJob) String() string {
 type hourlyJob struct {
 	startingPoint string
 	timeLocation  *time.Location
+	minute        int
 }
 
-func (hj hourlyJob) getInterval() time.Duration { return time.Hour }
-
-//calculate the sleep duration for hourly category
-//the time difference should be accounted on this
-func (hj hourlyJob) getSleepDuration(startTime time.Time) (time.Duration, error) {
-	var result time.Duration
+//validate the starting point and create the hourly category
+//empty starting point means it will run immediately
+func newHourlyJob(startingPoint string, loc *time.Location) (hourlyJob, error) {
+	result := hourlyJob{startingPoint: startingPoint, timeLocation: loc}
+	if loc == nil {
+		return result, TimeLocationError
+	}
 
 	//return if starting point is empty string
-	if hj.startingPoint == "" {
+	if startingPoint == "" {
 		return result, nil
 	}
 
 	//check whether the starting point is into minute parseable
-	if _, err := time.Parse("04", hj.startingPoint); err != nil {
+	if _, err := time.Parse("04", startingPoint); err != nil {
 		return result, StartingPointError
 	}
+	result.minute, _ = strconv.Atoi(startingPoint)
 
-	//save the start time on the location
-	//make sure it's not nil
-	if hj.timeLocation == nil {
-		return result, TimeLocationError
-	}
-	startTime = startTime.In(hj.timeLocation)
-	//this means that we have to wait for 0-59 minutes
-	//to be precise, parse the value in seconds
-	currentMinutes, currentSeconds := startTime.Format("04"), startTime.Format("05")
-	//assume that parse float64 should be successful
-	curMin, _ := strconv.ParseFloat(currentMinutes, 64)
-	curSec, _ := strconv.ParseFloat(currentSeconds, 64)
-
-	//calculate the total seconds passed
-	totalSec := curMin*60 + curSec
-
-	//also parse the starting point into float
-	//no need to handle error since it's already validated previously
-	mins, _ := strconv.ParseFloat(hj.startingPoint, 64)
-
-	//now we can calculate the duration in seconds
-	//add an hour if the startingSec is less than totalSec
-	//also add the time difference
-	startingSec := mins * 60
-	if startingSec < totalSec {
-		startingSec += 60 * 60
+	return result, nil
+}
+
+func (hj hourlyJob) getInterval() time.Duration { return time.Hour }
+func (hj hourlyJob) runsImmediately() bool      { return hj.startingPoint == "" }
+
+//calculate the next run for hourly category
+//the minute is checked in the time location, since not every time zone has a whole hour offset
+func (hj hourlyJob) Next(after time.Time) time.Time {
+	if hj.runsImmediately() {
+		return after.Add(time.Hour)
 	}
 
-	durFloat := math.Abs(startingSec - totalSec)
+	//go back to the start of the current hour and add the starting minute
+	//hours are counted in absolute time, so every real hour gets exactly one run
+	local := after.In(hj.timeLocation)
+	sinceHour := time.Duration(local.Minute())*time.Minute +
+		time.Duration(local.Second())*time.Second +
+		time.Duration(local.Nanosecond())
+	result := local.Add(-sinceHour).Add(time.Duration(hj.minute) * time.Minute)
+
+	//add an hour if it's already passed
+	if !result.After(after) {
+		result = result.Add(time.Hour)
+	}
 
-	//parse the duration into time.Duration
-	return time.ParseDuration(fmt.Sprintf("%.0fs", durFloat))
+	return result
 }
 
 func (hj hourlyJob) String() string {
@@ -120,35 +130,77 @@ func (hj hourlyJob) String() string {
 type dailyJob struct {
 	startingPoint string
 	timeLocation  *time.Location
+	dstPolicy     DSTPolicy
+	hour          int
+	minute        int
+	//the rest of the time of the day, only used if it runs immediately
+	second     int
+	nanosecond int
+	//whether the time of the day is already taken from the start
+	started bool
 }
 
-func (dj dailyJob) getInterval() time.Duration { return time.Hour * 24 }
-
-//calculate the sleep duration for daily category
-func (dj dailyJob) getSleepDuration(startTime time.Time) (time.Duration, error) {
-	var result time.Duration
+//validate the starting point and create the daily category
+//empty starting point means it will run immediately
+func newDailyJob(startingPoint string, loc *time.Location, policy DSTPolicy) (dailyJob, error) {
+	result := dailyJob{startingPoint: startingPoint, timeLocation: loc, dstPolicy: policy}
+	if loc == nil {
+		return result, TimeLocationError
+	}
 
 	//return if starting point is empty string
-	if dj.startingPoint == "" {
+	if startingPoint == "" {
 		return result, nil
 	}
 
 	//check whether starting point is into hour parseable
-	if _, err := time.Parse("1504", dj.startingPoint); err != nil {
-		return result, StartingPointError
+	var err error
+	if result.hour, result.minute, err = parseHourMinute(startingPoint); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+func (dj dailyJob) getInterval() time.Duration { return time.Hour * 24 }
+func (dj dailyJob) runsImmediately() bool      { return dj.startingPoint == "" }
+
+//if it runs immediately the time of the day is taken from the start
+//so a shifted run (e.g. because of DST) doesn't move the following ones
+func (dj dailyJob) startingAt(start time.Time) interval {
+	if !dj.runsImmediately() {
+		return dj
 	}
+	local := start.In(dj.timeLocation)
+	dj.hour, dj.minute, dj.second, dj.nanosecond = local.Hour(), local.Minute(), local.Second(), local.Nanosecond()
+	dj.started = true
+	return dj
+}
 
-	timeThen, _ := time.ParseInLocation("20060102", startTime.Format("20060102"), startTime.Location())
-	dur, _ := time.ParseDuration(fmt.Sprintf("%sh%sm", dj.startingPoint[:2], dj.startingPoint[2:]))
-	//add the time difference between server and the selected time location
-	timeThen = timeThen.Add(dur).In(dj.timeLocation).Add(calculateTimeDiff(dj.timeLocation))
+//calculate the next run for daily category
+//the hour is resolved in the time location on the day of the run itself
+//nonexistent and ambiguous hours are handled based on the DST policy
+func (dj dailyJob) Next(after time.Time) time.Time {
+	local := after.In(dj.timeLocation)
+
+	//if it runs immediately then it keeps the starting time of the day
+	//without the start the time of the day of the previous run is used
+	hour, minute, second, nsec := dj.hour, dj.minute, dj.second, dj.nanosecond
+	if dj.runsImmediately() && !dj.started {
+		hour, minute, second, nsec = local.Hour(), local.Minute(), local.Second(), local.Nanosecond()
+	}
 
-	//add 1 day to timeThen if it's before time now
-	if startTime.After(timeThen) {
-		timeThen = timeThen.AddDate(0, 0, 1)
+	//check today first, then the following days
+	//a day can only be skipped because of DST, so 3 days are more than enough
+	for i := 0; i < 3; i++ {
+		result := dj.dstPolicy.firstAfter(after, local.Year(), local.Month(), local.Day()+i,
+			hour, minute, second, nsec, dj.timeLocation)
+		if !result.IsZero() {
+			return result
+		}
 	}
 
-	return timeThen.Sub(startTime), nil
+	return time.Time{}
 }
 
 func (dj dailyJob) String() string {
@@ -168,105 +220,74 @@ func (dj dailyJob) String() string {
 type weeklyJob struct {
 	startingPoint string
 	timeLocation  *time.Location
+	dstPolicy     DSTPolicy
+	weekday       time.Weekday
+	hour          int
+	minute        int
 }
 
-//function to determine whether a weekday string is valid or not
-//valid one is e.g. "monday" or "mOnDAY" (will be formatted using strings library)
-//also return the number of seconds lapsed (e.g. for monday is 0 and for wednesday is 2 * 24 * 3600s)
-//if not valid then return error
-func getWeekDuration(weekday string) (time.Duration, error) {
-	//format it to become the title case
-	weekdayFormatted := strings.Title(strings.ToLower(weekday))
-
-	var numDay int
-
-	switch weekdayFormatted {
-	case "Monday":
-		numDay = 0
-	case "Tuesday":
-		numDay = 1
-	case "Wednesday":
-		numDay = 2
-	case "Thursday":
-		numDay = 3
-	case "Friday":
-		numDay = 4
-	case "Saturday":
-		numDay = 5
-	case "Sunday":
-		numDay = 6
-	default:
-		//return error if weekday is not a valid one
-		return time.Second * 0, fmt.Errorf("Invalid weekday string: %s", weekday)
-	}
-
-	//parse duration using the number of days
-	totalSec := numDay * 24 * 3600
-	return time.ParseDuration(fmt.Sprintf("%ds", totalSec))
-}
-
-func (wj weeklyJob) getInterval() time.Duration { return time.Hour * 24 * 7 }
-
-//calculate the sleep duration for weekly category
-func (wj weeklyJob) getSleepDuration(startTime time.Time) (time.Duration, error) {
-	var result time.Duration
-
-	//return if starting point is empty string
-	if wj.startingPoint == "" {
-		return result, nil
+//validate the starting point and create the weekly category
+func newWeeklyJob(startingPoint string, loc *time.Location, policy DSTPolicy) (weeklyJob, error) {
+	result := weeklyJob{startingPoint: startingPoint, timeLocation: loc, dstPolicy: policy}
+	if loc == nil {
+		return result, TimeLocationError
 	}
 
 	//weekly string should contains exactly 2 elements after splitted by @
-	weeklySplitted := strings.Split(wj.startingPoint, "@")
+	weeklySplitted := strings.Split(startingPoint, "@")
 	if len(weeklySplitted) != 2 {
 		return result, StartingPointError
 	}
 
-	//the idea is to substract the starting point converted in seconds by time now in seconds
-	//monday 00:00 is the starting point
-	//now create variable nowSec as total seconds from time now
-	var nowSec float64
-	wd := startTime.In(wj.timeLocation).Weekday().String()
-	if wdDur, err := getWeekDuration(wd); err != nil {
+	//validate the week first and then the hour
+	var err error
+	if result.weekday, err = parseWeekday(weeklySplitted[0]); err != nil {
 		return result, err
-	} else {
-		nowSec += wdDur.Seconds()
 	}
-
-	//now format the time now into hours and minute to be converted into seconds
-	//it is safe to assume that both of them are into float parseable
-	//add the hours and minutes into nowSec
-	hour, min := startTime.Format("15"), startTime.Format("04")
-	curHour, _ := strconv.ParseFloat(hour, 64)
-	curMin, _ := strconv.ParseFloat(min, 64)
-	nowSec += curHour*3600 + curMin*60
-
-	//now convert the starting point into seconds as well
-	var thenSec float64
-	if wdDur, err := getWeekDuration(weeklySplitted[0]); err != nil {
+	if result.hour, result.minute, err = parseHourMinute(weeklySplitted[1]); err != nil {
 		return result, err
-	} else {
-		thenSec += wdDur.Seconds()
 	}
 
-	//make sure that the second part is into time parseable in format hhmm
-	if _, err := time.Parse("1504", weeklySplitted[1]); err != nil {
-		return result, err
-	} else {
-		//now it is safe to assume that it is into duration in this manner parseable
-		dur, _ := time.ParseDuration(fmt.Sprintf("%sh%sm", weeklySplitted[1][:2], weeklySplitted[1][2:]))
-		thenSec += dur.Seconds()
+	return result, nil
+}
+
+//function to determine whether a weekday string is valid or not
+//valid one is e.g. "monday" or "mOnDAY" (it is case insensitive)
+//if not valid then return error
+func parseWeekday(weekday string) (time.Weekday, error) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.EqualFold(day.String(), weekday) {
+			return day, nil
+		}
 	}
 
-	//also add the time difference
-	thenSec += calculateTimeDiff(wj.timeLocation).Seconds()
+	//return error if weekday is not a valid one
+	return time.Sunday, fmt.Errorf("Invalid weekday string: %s", weekday)
+}
 
-	//add 1 week if thenSec is lesser than nowSec
-	if thenSec < nowSec {
-		thenSec += 7 * 24 * 3600
+func (wj weeklyJob) getInterval() time.Duration { return time.Hour * 24 * 7 }
+func (wj weeklyJob) runsImmediately() bool      { return false }
+
+//calculate the next run for weekly category
+//the hour is resolved in the time location on the day of the run itself
+//nonexistent and ambiguous hours are handled based on the DST policy
+func (wj weeklyJob) Next(after time.Time) time.Time {
+	local := after.In(wj.timeLocation)
+
+	//number of days until the next selected weekday (0 if it is today)
+	days := (int(wj.weekday) - int(local.Weekday()) + 7) % 7
+
+	//check this week first, then the following weeks
+	//a week can only be skipped because of DST, so 3 weeks are more than enough
+	for i := 0; i < 3; i++ {
+		result := wj.dstPolicy.firstAfter(after, local.Year(), local.Month(), local.Day()+days+7*i,
+			wj.hour, wj.minute, 0, 0, wj.timeLocation)
+		if !result.IsZero() {
+			return result
+		}
 	}
 
-	return time.ParseDuration(fmt.Sprintf("%.0fs", math.Abs(thenSec-nowSec)))
+	return time.Time{}
 }
 
 func (wj weeklyJob) String() string {
@@ -277,30 +298,148 @@ func (wj weeklyJob) String() string {
 	return fmt.Sprintf("[%s] %s", wj.getInterval(), startingPoint)
 }
 
-//calculate time difference between selected time location and server local time
-//for example if the server is run in UTC and the selected time is GMT (UTC+7)
-//then this function should yield 7hours
-func calculateTimeDiff(loc *time.Location) time.Duration {
-	serverTime := time.Now().Format("-0700")
-	localTime := time.Now().In(loc).Format("-0700")
-
-	//parse the time zone duration of server and local time
-	serverDur, _ := time.ParseDuration(fmt.Sprintf("%sh%sm", serverTime[1:3], serverTime[3:5]))
-	localDur, _ := time.ParseDuration(fmt.Sprintf("%sh%sm", localTime[1:3], localTime[3:5]))
-
-	//convert both durations into seconds float
-	//however if the sign is "-" multiply it with minus 1
-	serverSec := serverDur.Seconds()
-	if serverTime[:1] == "-" {
-		serverSec = serverSec * -1
+///////////////////////////////
+////////// MONTHLY ///////////
+/////////////////////////////
+
+//the interval is a month, which doesn't have a fixed duration
+//input is in format of "day hour" separated by @ symbol, where day is either:
+//a day of month, e.g. "1@0200" or "15@1530"
+//the last day of month, e.g. "L@2300"
+//the nth weekday of month, e.g. "Tuesday#2@0900" (second tuesday) or "Friday#L@1700" (last friday)
+//months without the selected day (e.g. 31st or fifth monday) are skipped, use "L" for the last day instead
+type monthlyJob struct {
+	startingPoint string
+	timeLocation  *time.Location
+	dstPolicy     DSTPolicy
+	//day of month, 0 if it's decided otherwise
+	day int
+	//whether it runs on the last day of month
+	lastDay bool
+	//nth weekday of month, -1 for the last one, 0 if it's decided otherwise
+	nth     int
+	weekday time.Weekday
+	hour    int
+	minute  int
+}
+
+//validate the starting point and create the monthly category
+func newMonthlyJob(startingPoint string, loc *time.Location, policy DSTPolicy) (monthlyJob, error) {
+	result := monthlyJob{startingPoint: startingPoint, timeLocation: loc, dstPolicy: policy}
+	if loc == nil {
+		return result, TimeLocationError
 	}
-	localSec := localDur.Seconds()
-	if localTime[:1] == "-" {
-		localSec = localSec * -1
+
+	//monthly string should contains exactly 2 elements after splitted by @
+	monthlySplitted := strings.Split(startingPoint, "@")
+	if len(monthlySplitted) != 2 {
+		return result, StartingPointError
 	}
 
-	//parse the difference between server and local time
-	result, _ := time.ParseDuration(fmt.Sprintf("%.0fs", serverSec-localSec))
+	var err error
+	if result.hour, result.minute, err = parseHourMinute(monthlySplitted[1]); err != nil {
+		return result, err
+	}
+
+	day := monthlySplitted[0]
+	switch {
+	case strings.EqualFold(day, "L"):
+		result.lastDay = true
+	case strings.Contains(day, "#"):
+		//nth weekday, the weekday itself should be valid as well
+		weekdaySplitted := strings.Split(day, "#")
+		if len(weekdaySplitted) != 2 {
+			return result, StartingPointError
+		}
+		if result.weekday, err = parseWeekday(weekdaySplitted[0]); err != nil {
+			return result, err
+		}
+		if strings.EqualFold(weekdaySplitted[1], "L") {
+			result.nth = -1
+		} else if result.nth, err = strconv.Atoi(weekdaySplitted[1]); err != nil || result.nth < 1 || result.nth > 5 {
+			return result, StartingPointError
+		}
+	default:
+		if result.day, err = strconv.Atoi(day); err != nil || result.day < 1 || result.day > 31 {
+			return result, StartingPointError
+		}
+	}
 
+	return result, nil
+}
+
+//the day of the selected month the job should run
+//return 0 if the month doesn't have it
+func (mj monthlyJob) dayIn(year int, month time.Month) int {
+	//day 0 of the next month is the last day of this month
+	daysInMonth := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+
+	switch {
+	case mj.lastDay:
+		return daysInMonth
+	case mj.day > daysInMonth:
+		return 0
+	case mj.day > 0:
+		return mj.day
+	case mj.nth < 0:
+		//go back from the last day until it's the selected weekday
+		lastWeekday := time.Date(year, month, daysInMonth, 0, 0, 0, 0, time.UTC).Weekday()
+		return daysInMonth - (int(lastWeekday)-int(mj.weekday)+7)%7
+	}
+
+	//go forward from the first day until it's the selected weekday, then add the weeks
+	firstWeekday := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC).Weekday()
+	result := 1 + (int(mj.weekday)-int(firstWeekday)+7)%7 + 7*(mj.nth-1)
+	if result > daysInMonth {
+		return 0
+	}
 	return result
 }
+
+func (mj monthlyJob) getInterval() time.Duration { return time.Hour * 24 * 30 }
+func (mj monthlyJob) runsImmediately() bool      { return false }
+
+//calculate the next run for monthly category
+//the hour is resolved in the time location on the day of the run itself
+//nonexistent and ambiguous hours are handled based on the DST policy
+func (mj monthlyJob) Next(after time.Time) time.Time {
+	local := after.In(mj.timeLocation)
+
+	//check this month first, then the following months
+	//fifth weekday is the rarest one, but it still happens at least once in a few months
+	for i := 0; i < 24; i++ {
+		year, month := local.Year(), local.Month()+time.Month(i)
+		//normalize the month overflowing the year
+		year, month = year+int(month-1)/12, (month-1)%12+1
+
+		day := mj.dayIn(year, month)
+		if day == 0 {
+			continue
+		}
+
+		result := mj.dstPolicy.firstAfter(after, year, month, day, mj.hour, mj.minute, 0, 0, mj.timeLocation)
+		if !result.IsZero() {
+			return result
+		}
+	}
+
+	return time.Time{}
+}
+
+//a month doesn't have a fixed duration, so it's printed as monthly
+func (mj monthlyJob) String() string {
+	return fmt.Sprintf("[monthly] %s", mj.startingPoint)
+}
+
+//parse hour and minute in format hhmm
+//return StartingPointError if it's not valid
+func parseHourMinute(hhmm string) (int, int, error) {
+	if _, err := time.Parse("1504", hhmm); err != nil {
+		return 0, 0, StartingPointError
+	}
+
+	//it is safe to assume that both of them are into int parseable
+	hour, _ := strconv.Atoi(hhmm[:2])
+	minute, _ := strconv.Atoi(hhmm[2:])
+	return hour, minute, nil
+}
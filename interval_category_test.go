@@ -6,85 +6,148 @@ import (
 	"time"
 )
 
-/*func TestCalculateTimeDiff(t *testing.T) {
-	loc, _ := time.LoadLocation("Europe/Berlin")
-	dur := calculateTimeDiff(loc)
-
-	assert.Equal(t, time.Hour*6, dur)
-}*/
-
 func TestHourlyInterval(t *testing.T) {
-	hj := hourlyJob{"123", globalTimeLoc}
-	interval := hj.getInterval()
-	assert.Equal(t, float64(3600), interval.Seconds())
-
-	time1, _ := time.Parse("20060102 1504", "20160712 0050")
-	_, err := hj.getSleepDuration(time1)
+	_, err := newHourlyJob("123", globalTimeLoc)
 	assert.Equal(t, StartingPointError, err)
 
-	hj.startingPoint = "60"
-	_, err = hj.getSleepDuration(time1)
+	_, err = newHourlyJob("60", globalTimeLoc)
 	assert.Equal(t, StartingPointError, err)
 
-	hj = hourlyJob{"30", globalTimeLoc}
-	timeDur, err := hj.getSleepDuration(time1)
-	assert.NoError(t, err)
-	assert.Equal(t, float64(40*60), timeDur.Seconds())
+	_, err = newHourlyJob("30", nil)
+	assert.Equal(t, TimeLocationError, err)
 
-	time2, _ := time.Parse("20060102 1504", "20160712 0010")
-	timeDur, err = hj.getSleepDuration(time2)
+	hj, err := newHourlyJob("30", globalTimeLoc)
 	assert.NoError(t, err)
-	assert.Equal(t, float64(20*60), timeDur.Seconds())
+	interval := hj.getInterval()
+	assert.Equal(t, float64(3600), interval.Seconds())
+	assert.Equal(t, false, hj.runsImmediately())
 
+	time1, _ := time.Parse("20060102 1504", "20160712 0050")
+	assert.Equal(t, float64(40*60), hj.Next(time1).Sub(time1).Seconds())
+
+	time2, _ := time.Parse("20060102 1504", "20160712 0010")
+	assert.Equal(t, float64(20*60), hj.Next(time2).Sub(time2).Seconds())
+
+	//exactly on the starting point means the next hour
+	time3, _ := time.Parse("20060102 1504", "20160712 0030")
+	assert.Equal(t, float64(3600), hj.Next(time3).Sub(time3).Seconds())
+
+	//time zone with half hour offset should still run on minute 30 local time
+	kolkata, _ := time.LoadLocation("Asia/Kolkata")
+	hj, _ = newHourlyJob("30", kolkata)
+	next := hj.Next(time1)
+	assert.Equal(t, 30, next.In(kolkata).Minute())
+	assert.Equal(t, float64(10*60), next.Sub(time1).Seconds())
+
+	hj, _ = newHourlyJob("", globalTimeLoc)
+	assert.Equal(t, true, hj.runsImmediately())
+	assert.Equal(t, time1.Add(time.Hour), hj.Next(time1))
 }
 
 func TestDailyInterval(t *testing.T) {
 	loc, _ := time.LoadLocation("Europe/Berlin")
-	dj := dailyJob{"1330", loc}
+	dj, err := newDailyJob("1330", loc, DSTPolicy{})
+	assert.NoError(t, err)
 	interval := dj.getInterval()
 	assert.Equal(t, float64(24*3600), interval.Seconds())
 
-	timeDiff := calculateTimeDiff(loc)
-	time1, _ := time.Parse("20060102 1504", "20160521 1000")
-	dur, err := dj.getSleepDuration(time1.Add(timeDiff))
-	assert.NoError(t, err)
-	assert.Equal(t, float64(3.5*3600), dur.Seconds())
+	_, err = newDailyJob("2561", loc, DSTPolicy{})
+	assert.Equal(t, StartingPointError, err)
 
-	time2, _ := time.Parse("20060102 1504", "20160521 1800")
-	dur, err = dj.getSleepDuration(time2.Add(timeDiff))
-	assert.NoError(t, err)
-	assert.Equal(t, float64(19.5*3600), dur.Seconds())
+	time1, _ := time.ParseInLocation("20060102 1504", "20160521 1000", loc)
+	assert.Equal(t, float64(3.5*3600), dj.Next(time1).Sub(time1).Seconds())
+
+	time2, _ := time.ParseInLocation("20060102 1504", "20160521 1800", loc)
+	assert.Equal(t, float64(19.5*3600), dj.Next(time2).Sub(time2).Seconds())
+
+	//the input time can be in any location
+	assert.Equal(t, float64(19.5*3600), dj.Next(time2.UTC()).Sub(time2).Seconds())
 }
 
-func TestGetWeekDuration(t *testing.T) {
-	dur, err := getWeekDuration("monDaY")
+func TestParseWeekday(t *testing.T) {
+	day, err := parseWeekday("monDaY")
 	assert.NoError(t, err)
-	assert.Equal(t, float64(0), dur.Seconds())
+	assert.Equal(t, time.Monday, day)
 
-	_, err = getWeekDuration("seniN")
+	_, err = parseWeekday("seniN")
 	assert.Error(t, err)
 
-	dur, err = getWeekDuration("friday")
+	day, err = parseWeekday("friday")
 	assert.NoError(t, err)
-	assert.Equal(t, float64(4*24*3600), dur.Seconds())
+	assert.Equal(t, time.Friday, day)
 }
 
 func TestWeeklyInterval(t *testing.T) {
 	loc, _ := time.LoadLocation("Europe/Berlin")
-	timeDiff := calculateTimeDiff(loc)
 
-	wj := weeklyJob{"Wednesday@1530", loc}
-	timeNow, _ := time.Parse("2006-01-02 15:04", "2016-11-04 10:00") //Friday
-	timeNow = timeNow.Add(timeDiff)
+	_, err := newWeeklyJob("Wednesday 1530", loc, DSTPolicy{})
+	assert.Equal(t, StartingPointError, err)
 
-	dur, err := wj.getSleepDuration(timeNow)
+	wj, err := newWeeklyJob("Wednesday@1530", loc, DSTPolicy{})
 	assert.NoError(t, err)
-	assert.Equal(t, float64(5*24*3600+55*360), dur.Seconds())
 
-	timeNow, _ = time.Parse("2006-01-02 15:04", "2016-11-01 10:00") //Tuesday
-	timeNow = timeNow.Add(timeDiff)
+	timeNow, _ := time.ParseInLocation("2006-01-02 15:04", "2016-11-04 10:00", loc) //Friday
+	assert.Equal(t, float64(5*24*3600+55*360), wj.Next(timeNow).Sub(timeNow).Seconds())
+
+	timeNow, _ = time.ParseInLocation("2006-01-02 15:04", "2016-11-01 10:00", loc) //Tuesday
+	assert.Equal(t, float64(24*3600+55*360), wj.Next(timeNow).Sub(timeNow).Seconds())
+
+	//on the same weekday but already passed
+	timeNow, _ = time.ParseInLocation("2006-01-02 15:04", "2016-11-02 16:00", loc) //Wednesday
+	assert.Equal(t, float64(7*24*3600-30*60), wj.Next(timeNow).Sub(timeNow).Seconds())
+}
+
+func TestCustomIntervalNext(t *testing.T) {
+	cij := customIntervalJob{time.Second * 30}
+	timeNow := time.Now()
+	assert.Equal(t, true, cij.runsImmediately())
+	assert.Equal(t, timeNow.Add(time.Second*30), cij.Next(timeNow))
+}
+
+func TestMonthlyInterval(t *testing.T) {
+	invalids := []string{"", "1", "0@0200", "32@0200", "1@2500", "X@0200", "Tuesday#0@0900",
+		"Tuesday#6@0900", "Selasa#2@0900", "Tuesday#2#3@0900", "1@0200@0300"}
+	for _, monthly := range invalids {
+		_, err := newMonthlyJob(monthly, globalTimeLoc, DSTPolicy{})
+		assert.Error(t, err, monthly)
+	}
+
+	parse := func(s string) time.Time {
+		result, _ := time.ParseInLocation("2006-01-02 15:04", s, globalTimeLoc)
+		return result
+	}
 
-	dur, err = wj.getSleepDuration(timeNow)
+	mj, err := newMonthlyJob("1@0200", globalTimeLoc, DSTPolicy{})
 	assert.NoError(t, err)
-	assert.Equal(t, float64(24*3600+55*360), dur.Seconds())
+	assert.Equal(t, false, mj.runsImmediately())
+	assert.Equal(t, "[monthly] 1@0200", mj.String())
+	assert.Equal(t, parse("2016-11-01 02:00"), mj.Next(parse("2016-10-31 23:00")))
+	assert.Equal(t, parse("2016-12-01 02:00"), mj.Next(parse("2016-11-01 02:00")))
+	assert.Equal(t, parse("2017-01-01 02:00"), mj.Next(parse("2016-12-15 02:00")))
+
+	//short months are skipped
+	mj, _ = newMonthlyJob("31@1200", globalTimeLoc, DSTPolicy{})
+	assert.Equal(t, parse("2016-12-31 12:00"), mj.Next(parse("2016-10-31 12:00")))
+	assert.Equal(t, parse("2017-03-31 12:00"), mj.Next(parse("2017-01-31 12:00")))
+
+	//last day of month
+	mj, _ = newMonthlyJob("l@2300", globalTimeLoc, DSTPolicy{})
+	assert.Equal(t, parse("2016-02-29 23:00"), mj.Next(parse("2016-02-01 00:00")))
+	assert.Equal(t, parse("2017-02-28 23:00"), mj.Next(parse("2017-01-31 23:00")))
+	assert.Equal(t, parse("2017-04-30 23:00"), mj.Next(parse("2017-03-31 23:00")))
+
+	//second tuesday
+	mj, _ = newMonthlyJob("Tuesday#2@0900", globalTimeLoc, DSTPolicy{})
+	assert.Equal(t, parse("2016-11-08 09:00"), mj.Next(parse("2016-11-01 09:00")))
+	assert.Equal(t, parse("2016-12-13 09:00"), mj.Next(parse("2016-11-08 09:00")))
+
+	//fifth monday only exists in some months
+	mj, _ = newMonthlyJob("monday#5@0900", globalTimeLoc, DSTPolicy{})
+	assert.Equal(t, parse("2017-01-30 09:00"), mj.Next(parse("2016-10-31 09:00")))
+
+	//last friday
+	mj, _ = newMonthlyJob("Friday#L@1700", globalTimeLoc, DSTPolicy{})
+	assert.Equal(t, parse("2016-11-25 17:00"), mj.Next(parse("2016-11-01 00:00")))
+	assert.Equal(t, parse("2016-12-30 17:00"), mj.Next(parse("2016-11-25 17:00")))
+	assert.Equal(t, parse("2017-03-31 17:00"), mj.Next(parse("2017-03-30 00:00")))
 }
@@ -0,0 +1,121 @@
+//optional configurations that can be passed into the constructors
+package gover
+
+import (
+	"fmt"
+	"time"
+)
+
+//the option configures the scheduler or the retry function
+//it returns an error if the given value is not valid or the option can't be used by the constructor
+type Option func(*config) error
+
+//the constructor that the options are given to
+type optionTarget int
+
+const (
+	//New and Do
+	targetGover optionTarget = iota
+	//the gotermins
+	targetScheduler
+	//the default options of the crontab, they are checked again by every registered gotermin
+	targetCrontab
+)
+
+func (ot optionTarget) String() string {
+	if ot == targetGover {
+		return "gover"
+	}
+	return "the scheduler"
+}
+
+//the collection of all optional configurations
+//each constructor only picks the ones that are relevant for it
+type config struct {
+	//the constructor that the options are given to
+	target optionTarget
+	//how to handle the wall clock time around daylight saving time transitions
+	dstPolicy DSTPolicy
+	//source of the time, default is the system clock
+	clock Clock
+	//what gotermin does when a job runs longer than its interval
+	overlapPolicy OverlapPolicy
+	//receives the recovered panics of the jobs
+	panicHandler func(error)
+	//retry configuration of gover or the scheduled jobs, nil means no retry
+	retry *RetryConfig
+	//how long gover can try, 0 means until the context is done
+	timeout time.Duration
+	//how long each attempt of gover can last, 0 means until the whole run is done
+	attemptTimeout time.Duration
+	//observe the runs of gover
+	hooks Hooks
+	//guard the attempts of gover, nil means every attempt is allowed
+	circuitBreaker *CircuitBreaker
+	//limit the retries of gover, nil means no limit
+	retryBudget *RetryBudget
+	//limit the attempts of gover that run at once, nil means no limit
+	bulkhead *Bulkhead
+	//keep the attempts of gover under the rate, nil means no limit
+	rateLimiter *RateLimiter
+	//start another attempt of gover if the running ones are too slow, 0 means no hedging
+	hedgeDelay time.Duration
+	//maximum number of the attempts of gover that can run at once while hedging
+	maxParallel int
+	//the options that are set, but only change the retried runs of the scheduler
+	retriedOptions []string
+}
+
+//apply all options of the constructor on top of the default configuration
+//return the first error found
+func newConfig(target optionTarget, opts []Option) (config, error) {
+	result := config{target: target, clock: realClock{}}
+	for _, opt := range opts {
+		if err := opt(&result); err != nil {
+			return result, err
+		}
+	}
+
+	//without the retry the run of the scheduler simply calls the job, so these options would do nothing
+	if target == targetScheduler && result.retry == nil && len(result.retriedOptions) > 0 {
+		return result, fmt.Errorf("%s can't be used by the scheduler without the retry, see WithRetry", result.retriedOptions[0])
+	}
+	return result, nil
+}
+
+//return error if the option is not meant for the constructor, the crontab counts as the scheduler
+func (c *config) only(target optionTarget, option string) error {
+	if (c.target == targetGover) != (target == targetGover) {
+		return fmt.Errorf("%s can't be used by %s", option, c.target)
+	}
+	return nil
+}
+
+//remember the option that the scheduler only uses for the retried runs
+func (c *config) retried(option string) {
+	c.retriedOptions = append(c.retriedOptions, option)
+}
+
+//get the retry configuration, create an empty one if there's none yet
+func (c *config) retryConfig() *RetryConfig {
+	if c.retry == nil {
+		c.retry = &RetryConfig{}
+	}
+	return c.retry
+}
+
+//set the policy for nonexistent and ambiguous wall clock time
+//default is shifting forward the nonexistent time and running only on the first ambiguous time
+//it's only accepted by the schedulers
+func WithDSTPolicy(policy DSTPolicy) Option {
+	return func(c *config) error {
+		if err := c.only(targetScheduler, "WithDSTPolicy"); err != nil {
+			return err
+		}
+		if err := policy.validate(); err != nil {
+			return err
+		}
+		c.dstPolicy = policy
+		return nil
+	}
+}
@@ -0,0 +1,51 @@
+//overlap policy decides what gotermin does when a job runs longer than its interval
+package gover
+
+import "fmt"
+
+//what to do if the previous run is still running when the next one is due
+type OverlapPolicy int
+
+const (
+	//run both of them concurrently
+	//the context of each run expires when the next one is due
+	OverlapAllow OverlapPolicy = iota
+	//skip the new run
+	//the context of each run lasts until the scheduler is stopped
+	OverlapSkip
+	//start the new run right after the previous one finishes
+	//only one run can wait, the others are skipped
+	//the context of each run lasts until the scheduler is stopped
+	OverlapQueue
+	//cancel the context of the previous run and start the new one
+	OverlapReplace
+)
+
+func (op OverlapPolicy) String() string {
+	switch op {
+	case OverlapAllow:
+		return "allow"
+	case OverlapSkip:
+		return "skip"
+	case OverlapQueue:
+		return "queue"
+	case OverlapReplace:
+		return "replace"
+	}
+	return fmt.Sprintf("OverlapPolicy(%d)", int(op))
+}
+
+//set what to do if the previous run is still running when the next one is due
+//default is running both of them concurrently, it's only accepted by the schedulers
+func WithOverlapPolicy(policy OverlapPolicy) Option {
+	return func(c *config) error {
+		if err := c.only(targetScheduler, "WithOverlapPolicy"); err != nil {
+			return err
+		}
+		if policy < OverlapAllow || policy > OverlapReplace {
+			return fmt.Errorf("Invalid overlap policy: %d", policy)
+		}
+		c.overlapPolicy = policy
+		return nil
+	}
+}
@@ -0,0 +1,129 @@
+package gover
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+//wait until the condition is fulfilled, fail if it takes more than a second
+func eventually(t *testing.T, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition is not fulfilled in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+//create a custom interval gotermin whose job blocks until released or cancelled
+func newBlockingGotermin(t *testing.T, policy OverlapPolicy) (*Gotermin, *FakeClock, chan struct{}, chan error) {
+	clock := NewFakeClock(time.Now())
+	release := make(chan struct{})
+	done := make(chan error, 10)
+	job := func(ctx context.Context) {
+		select {
+		case <-release:
+			done <- nil
+		case <-ctx.Done():
+			done <- ctx.Err()
+		}
+	}
+
+	gt, err := NewCustomInterval(job, time.Second, globalTimeLoc, WithClock(clock), WithOverlapPolicy(policy))
+	assert.NoError(t, err)
+	assert.NoError(t, gt.Start())
+	eventually(t, func() bool { return gt.Stats().Runs == 1 })
+
+	return gt, clock, release, done
+}
+
+func runningJobs(gt *Gotermin) int {
+	gt.mu.Lock()
+	defer gt.mu.Unlock()
+	return gt.running
+}
+
+//only the counters of the overlap policy from the stats
+func runCounters(gt *Gotermin) Stats {
+	stats := gt.Stats()
+	return Stats{Runs: stats.Runs, Skipped: stats.Skipped, Queued: stats.Queued, Replaced: stats.Replaced}
+}
+
+func TestOverlapPolicyOption(t *testing.T) {
+	_, err := NewCustomInterval(randomFunc, time.Second, globalTimeLoc, WithOverlapPolicy(OverlapPolicy(4)))
+	assert.Error(t, err)
+
+	crontab, _ := NewCrontab(globalTimeLoc, WithOverlapPolicy(OverlapSkip))
+	assert.NoError(t, crontab.RegisterNewHourly("foo", randomFunc, "30"))
+	assert.NoError(t, crontab.RegisterNewHourly("bar", randomFunc, "30", WithOverlapPolicy(OverlapQueue)))
+	assert.Equal(t, OverlapSkip, crontab.cronjobs["foo"].overlapPolicy)
+	assert.Equal(t, OverlapQueue, crontab.cronjobs["bar"].overlapPolicy)
+
+	assert.Equal(t, "replace", OverlapReplace.String())
+}
+
+func TestOverlapAllow(t *testing.T) {
+	gt, clock, release, done := newBlockingGotermin(t, OverlapAllow)
+
+	//the context of the first run expires when the second one is due
+	clock.Advance(time.Second)
+	assert.Equal(t, context.DeadlineExceeded, <-done)
+	eventually(t, func() bool { return gt.Stats().Runs == 2 })
+
+	close(release)
+	eventually(t, func() bool { return runningJobs(gt) == 0 })
+	assert.Equal(t, Stats{Runs: 2}, runCounters(gt))
+	assert.NoError(t, gt.Stop())
+}
+
+func TestOverlapSkip(t *testing.T) {
+	gt, clock, release, _ := newBlockingGotermin(t, OverlapSkip)
+
+	clock.Advance(time.Second)
+	eventually(t, func() bool { return gt.Stats().Skipped == 1 })
+	clock.Advance(time.Second)
+	eventually(t, func() bool { return gt.Stats().Skipped == 2 })
+	assert.Equal(t, int64(1), gt.Stats().Runs)
+
+	//once it's finished the next one can run again
+	close(release)
+	eventually(t, func() bool { return runningJobs(gt) == 0 })
+	clock.Advance(time.Second)
+	eventually(t, func() bool { return gt.Stats().Runs == 2 })
+	assert.NoError(t, gt.Stop())
+}
+
+func TestOverlapQueue(t *testing.T) {
+	gt, clock, release, done := newBlockingGotermin(t, OverlapQueue)
+
+	clock.Advance(time.Second)
+	eventually(t, func() bool { return gt.Stats().Queued == 1 })
+	clock.Advance(time.Second)
+	eventually(t, func() bool { return gt.Stats().Skipped == 1 })
+	assert.Equal(t, int64(1), gt.Stats().Runs)
+
+	//the queued one starts right after the first one is finished
+	release <- struct{}{}
+	assert.NoError(t, <-done)
+	eventually(t, func() bool { return gt.Stats().Runs == 2 })
+	assert.Equal(t, Stats{Runs: 2, Skipped: 1, Queued: 1}, runCounters(gt))
+
+	close(release)
+	assert.NoError(t, gt.Stop())
+}
+
+func TestOverlapReplace(t *testing.T) {
+	gt, clock, release, done := newBlockingGotermin(t, OverlapReplace)
+
+	clock.Advance(time.Second)
+	assert.Equal(t, context.Canceled, <-done)
+	eventually(t, func() bool { return gt.Stats().Runs == 2 })
+	assert.Equal(t, int64(1), gt.Stats().Replaced)
+
+	close(release)
+	assert.NoError(t, gt.Stop())
+}
@@ -0,0 +1,64 @@
+//panic recovery for the jobs
+//a panic in the job should not kill the whole process, it's converted into PanicError instead
+package gover
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+//error created from a recovered panic in a job
+type PanicError struct {
+	//key of the gotermin in crontab, empty if it's not registered in any crontab
+	Key string
+	//the value given to panic
+	Value interface{}
+	//stack trace of the goroutine at the moment of the panic
+	Stack []byte
+}
+
+func (pe *PanicError) Error() string {
+	if pe.Key == "" {
+		return fmt.Sprintf("Job panicked: %v", pe.Value)
+	}
+	return fmt.Sprintf("Job %s panicked: %v", pe.Key, pe.Value)
+}
+
+//the panic value if it's an error itself
+func (pe *PanicError) Unwrap() error {
+	if err, ok := pe.Value.(error); ok {
+		return err
+	}
+	return nil
+}
+
+//set the function that receives the recovered panics as PanicError
+//gotermin prints them by default, gover returns them as the job error
+func WithPanicHandler(handler func(error)) Option {
+	return func(c *config) error {
+		if handler == nil {
+			return fmt.Errorf("Please input a valid panic handler")
+		}
+		c.panicHandler = handler
+		return nil
+	}
+}
+
+//run the function and convert a panic into PanicError
+func callSafely(key string, fn func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Key: key, Value: r, Stack: debug.Stack()}
+		}
+	}()
+	return fn()
+}
+
+//default handler of gotermin, simply print the panic and its stack trace
+func printPanic(err error) {
+	if pe, ok := err.(*PanicError); ok {
+		fmt.Printf("%s\n%s\n", pe, pe.Stack)
+		return
+	}
+	fmt.Println(err)
+}
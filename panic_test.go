@@ -0,0 +1,83 @@
+package gover
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCallSafely(t *testing.T) {
+	assert.NoError(t, callSafely("foo", func() error { return nil }))
+
+	jobErr := errors.New("job error")
+	assert.Equal(t, jobErr, callSafely("foo", func() error { return jobErr }))
+
+	err := callSafely("foo", func() error { panic("meow") })
+	pe, ok := err.(*PanicError)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, "foo", pe.Key)
+	assert.Equal(t, "meow", pe.Value)
+	assert.Equal(t, "Job foo panicked: meow", pe.Error())
+	assert.Equal(t, true, strings.Contains(string(pe.Stack), "TestCallSafely"))
+	assert.Nil(t, errors.Unwrap(pe))
+
+	err = callSafely("", func() error { panic(jobErr) })
+	assert.Equal(t, "Job panicked: job error", err.Error())
+	assert.Equal(t, true, errors.Is(err, jobErr))
+
+	_, err = NewHourly(randomFunc, "30", globalTimeLoc, WithPanicHandler(nil))
+	assert.Error(t, err)
+}
+
+func TestGoterminPanic(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	panics := make(chan error, 10)
+	crontab, _ := NewCrontab(globalTimeLoc, WithClock(clock), WithPanicHandler(func(err error) { panics <- err }))
+
+	err := crontab.RegisterNewCustomInterval("grumpy", func(ctx context.Context) {
+		panic("hiss")
+	}, time.Second)
+	assert.NoError(t, err)
+	assert.NoError(t, crontab.Start("grumpy"))
+
+	//the schedule keeps running after the panic
+	err = <-panics
+	assert.Equal(t, "Job grumpy panicked: hiss", err.Error())
+	clock.Advance(time.Second)
+	<-panics
+
+	gt, _ := crontab.GetCronjob("grumpy")
+	eventually(t, func() bool { return gt.Stats().Panics == 2 })
+	assert.Equal(t, true, gt.IsActive())
+	assert.NoError(t, crontab.Shutdown(context.Background()))
+}
+
+func TestGoverPanic(t *testing.T) {
+	var panics []error
+	tryNum := 0
+	job := func(ctx context.Context) error {
+		tryNum += 1
+		if tryNum == 1 {
+			panic(fmt.Sprintf("try %d", tryNum))
+		}
+		return nil
+	}
+
+	//panic is retried like any other error
+	gover, _ := New(job, WithTimeout(time.Second), WithPanicHandler(func(err error) { panics = append(panics, err) }))
+	gover.MaxRetry = 1
+	assert.NoError(t, gover.Run(context.Background()))
+	assert.Equal(t, 2, tryNum)
+	assert.Equal(t, 1, len(panics))
+	assert.Equal(t, "Job panicked: try 1", panics[0].Error())
+
+	//without retry the panic ends the run
+	tryNum = 0
+	gover, _ = New(job, WithTimeout(time.Second))
+	assert.Error(t, gover.Run(context.Background()))
+	assert.Equal(t, 1, tryNum)
+}
@@ -0,0 +1,135 @@
+//rate limiter keeps the attempts of the jobs under the agreed number of requests per second
+//it can be shared by many govers, so all attempts and all callers together respect the limit
+package gover
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+//the token is not available and the attempt is not allowed to wait for it
+var errRateLimited = errors.New("Rate limit is exceeded")
+
+//the configuration of the rate limiter
+type RateLimiterConfig struct {
+	//number of the attempts that are allowed every second
+	Rate float64
+	//maximum number of the attempts that can start at once after being idle, default is 1
+	Burst int
+	//source of the time that refills the bucket, nil means the system clock
+	Clock Clock
+}
+
+//make sure that the configuration can be used by the rate limiter
+func (rc RateLimiterConfig) validate() error {
+	if rc.Rate <= 0 {
+		return fmt.Errorf("Invalid rate: %v", rc.Rate)
+	}
+	if rc.Burst < 0 {
+		return fmt.Errorf("Invalid burst: %d", rc.Burst)
+	}
+	return nil
+}
+
+//the token bucket rate limiter that's attached to gover with WithRateLimiter
+//every attempt takes a token before it starts and waits for it if the bucket is empty
+//the bucket is refilled with Rate tokens every second up to Burst tokens
+//it's safe for concurrent use
+type RateLimiter struct {
+	settings RateLimiterConfig
+	clock    Clock
+
+	mu sync.Mutex
+	//the tokens at the last update, negative if the tokens are already reserved by the waiting attempts
+	tokens float64
+	last   time.Time
+}
+
+//create the rate limiter with the configuration, the bucket starts full
+//return error if the configuration is not valid
+func NewRateLimiter(settings RateLimiterConfig) (*RateLimiter, error) {
+	if err := settings.validate(); err != nil {
+		return nil, err
+	}
+	if settings.Burst == 0 {
+		settings.Burst = 1
+	}
+	if settings.Clock == nil {
+		settings.Clock = realClock{}
+	}
+
+	return &RateLimiter{
+		settings: settings,
+		clock:    settings.Clock,
+		tokens:   float64(settings.Burst),
+		last:     settings.Clock.Now(),
+	}, nil
+}
+
+//limit the attempts of gover with the rate limiter, every attempt waits for its token within the deadline of the run
+func WithRateLimiter(limiter *RateLimiter) Option {
+	return func(c *config) error {
+		if limiter == nil {
+			return fmt.Errorf("Please input a valid rate limiter")
+		}
+		c.rateLimiter = limiter
+		c.retried("WithRateLimiter")
+		return nil
+	}
+}
+
+//take a token for the attempt, wait for it if it's allowed
+//return context.DeadlineExceeded immediately if the token is not available before the deadline of the context
+//return errRateLimited if it's not allowed to wait, the context error if it's done while waiting
+func (rl *RateLimiter) wait(ctx context.Context, wait bool) error {
+	rl.mu.Lock()
+	now := rl.clock.Now()
+	rl.refill(now)
+
+	//reserve the token, the waiting duration depends on how many are reserved before
+	delay := time.Duration(0)
+	if rl.tokens < 1 {
+		if !wait {
+			rl.mu.Unlock()
+			return errRateLimited
+		}
+		delay = time.Duration((1 - rl.tokens) / rl.settings.Rate * float64(time.Second))
+		if deadline, ok := ctx.Deadline(); ok && now.Add(delay).After(deadline) {
+			rl.mu.Unlock()
+			return context.DeadlineExceeded
+		}
+	}
+	rl.tokens--
+	rl.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := rl.clock.NewTimer(delay)
+	select {
+	case <-timer.C():
+		return nil
+	case <-ctx.Done():
+		//give back the reserved token, so the other attempts don't have to wait for it
+		timer.Stop()
+		rl.mu.Lock()
+		rl.tokens++
+		rl.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+//add the tokens since the last update
+func (rl *RateLimiter) refill(now time.Time) {
+	if elapsed := now.Sub(rl.last); elapsed > 0 {
+		rl.tokens += elapsed.Seconds() * rl.settings.Rate
+		if burst := float64(rl.settings.Burst); rl.tokens > burst {
+			rl.tokens = burst
+		}
+		rl.last = now
+	}
+}
@@ -0,0 +1,90 @@
+package gover
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterConfig(t *testing.T) {
+	for _, settings := range []RateLimiterConfig{
+		{},
+		{Rate: -1},
+		{Rate: 1, Burst: -1},
+	} {
+		_, err := NewRateLimiter(settings)
+		assert.Error(t, err, settings)
+	}
+
+	_, err := New(func(ctx context.Context) error { return nil }, WithRateLimiter(nil))
+	assert.Error(t, err)
+}
+
+func TestRateLimiter(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	limiter, err := NewRateLimiter(RateLimiterConfig{Rate: 2, Burst: 2, Clock: clock})
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	//the bucket starts full
+	assert.NoError(t, limiter.wait(ctx, true))
+	assert.NoError(t, limiter.wait(ctx, true))
+	assert.Equal(t, errRateLimited, limiter.wait(ctx, false))
+
+	//the next token comes after half a second
+	result := make(chan error, 1)
+	go func() { result <- limiter.wait(ctx, true) }()
+	clock.BlockUntil(1)
+	clock.Advance(time.Millisecond * 499)
+	assert.Equal(t, 0, len(result))
+	clock.Advance(time.Millisecond)
+	assert.NoError(t, <-result)
+
+	//don't wait if the token can't come before the deadline
+	short, cancelShort := withClockDeadline(ctx, clock, clock.Now().Add(time.Millisecond*100))
+	defer cancelShort()
+	assert.Equal(t, context.DeadlineExceeded, limiter.wait(short, true))
+
+	//the cancelled waiting gives back its token
+	cancelled, cancel := context.WithCancel(ctx)
+	go func() { result <- limiter.wait(cancelled, true) }()
+	clock.BlockUntil(2)
+	cancel()
+	assert.Equal(t, context.Canceled, <-result)
+	clock.Advance(time.Millisecond * 500)
+	assert.NoError(t, limiter.wait(ctx, false))
+}
+
+func TestGoverRateLimiter(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	start := clock.Now()
+	limiter, _ := NewRateLimiter(RateLimiterConfig{Rate: 1, Clock: clock})
+
+	var tries []time.Time
+	job := func(ctx context.Context) error {
+		tries = append(tries, clock.Now())
+		if len(tries) < 2 {
+			return fmt.Errorf("not yet")
+		}
+		return nil
+	}
+
+	//the retry waits for the next token, even without retry interval
+	gover, err := New(job, WithClock(clock), WithRateLimiter(limiter), WithMaxRetry(3), WithRetryInterval(0))
+	assert.NoError(t, err)
+	result := make(chan error, 1)
+	go func() { result <- gover.Run(context.Background()) }()
+	clock.BlockUntil(1)
+	clock.Advance(time.Second)
+	assert.NoError(t, <-result)
+	assert.Equal(t, []time.Time{start, start.Add(time.Second)}, tries)
+
+	//the other gover can't get any token before its deadline
+	other, _ := New(job, WithClock(clock), WithRateLimiter(limiter), WithTimeout(time.Millisecond*500))
+	err = other.Run(context.Background())
+	assert.Equal(t, true, errors.Is(err, ErrDeadline))
+	assert.Equal(t, 2, len(tries))
+}
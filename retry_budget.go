@@ -0,0 +1,144 @@
+//retry budget limits the retries of many govers together
+//during an incident the retries can't multiply the load, since only a part of the recent successful calls can be retried
+package gover
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+//the configuration of the retry budget
+type RetryBudgetConfig struct {
+	//number of retries that each successful attempt allows, e.g. 0.1 means one retry for every ten successes
+	Ratio float64
+	//number of retries that are allowed every second on average, even without any success
+	MinPerSecond int
+	//how long the successes and the retries are counted, default is 10 seconds
+	//it's rounded down to whole seconds
+	TTL time.Duration
+	//source of the time that decides when the successes and retries expire, nil means the system clock
+	Clock Clock
+}
+
+//make sure that the configuration can be used by the retry budget
+func (bc RetryBudgetConfig) validate() error {
+	if bc.Ratio < 0 {
+		return fmt.Errorf("Invalid retry ratio: %v", bc.Ratio)
+	}
+	if bc.MinPerSecond < 0 {
+		return fmt.Errorf("Invalid minimum number of retries per second: %d", bc.MinPerSecond)
+	}
+	if bc.Ratio == 0 && bc.MinPerSecond == 0 {
+		return fmt.Errorf("Please input the retry ratio or the minimum number of retries per second")
+	}
+	if bc.TTL != 0 && bc.TTL < time.Second {
+		return fmt.Errorf("TTL %s of the retry budget is too short", bc.TTL)
+	}
+	return nil
+}
+
+//the successes and retries within a single second
+type budgetBucket struct {
+	second    int64
+	successes int
+	retries   int
+}
+
+//the retry budget that's attached to gover with WithRetryBudget
+//it works like a token bucket: every success deposits Ratio tokens and every retry withdraws one
+//the tokens expire after TTL and MinPerSecond tokens are always reserved
+//it's safe for concurrent use
+type RetryBudget struct {
+	settings RetryBudgetConfig
+	clock    Clock
+
+	mu sync.Mutex
+	//one bucket for every second within the TTL
+	buckets []budgetBucket
+}
+
+//create the retry budget with the configuration, the same budget can be given to many govers
+//return error if the configuration is not valid
+func NewRetryBudget(settings RetryBudgetConfig) (*RetryBudget, error) {
+	if err := settings.validate(); err != nil {
+		return nil, err
+	}
+	if settings.TTL == 0 {
+		settings.TTL = time.Second * 10
+	}
+	if settings.Clock == nil {
+		settings.Clock = realClock{}
+	}
+
+	return &RetryBudget{
+		settings: settings,
+		clock:    settings.Clock,
+		buckets:  make([]budgetBucket, int(settings.TTL/time.Second)),
+	}, nil
+}
+
+//share the retry budget between govers, the run stops retrying with ErrRetryBudgetExhausted once it's spent
+func WithRetryBudget(budget *RetryBudget) Option {
+	return func(c *config) error {
+		if budget == nil {
+			return fmt.Errorf("Please input a valid retry budget")
+		}
+		c.retryBudget = budget
+		c.retried("WithRetryBudget")
+		return nil
+	}
+}
+
+//number of retries that are currently allowed
+func (rb *RetryBudget) Available() int {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+	return int(rb.balance(rb.clock.Now().Unix()))
+}
+
+//record the successful attempt
+func (rb *RetryBudget) deposit() {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+	rb.bucket(rb.clock.Now().Unix()).successes++
+}
+
+//take a retry from the budget
+//return false if it's already spent
+func (rb *RetryBudget) withdraw() bool {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+	second := rb.clock.Now().Unix()
+	if rb.balance(second) < 1 {
+		return false
+	}
+	rb.bucket(second).retries++
+	return true
+}
+
+//the bucket of the given second, the expired one is reused
+func (rb *RetryBudget) bucket(second int64) *budgetBucket {
+	index := second % int64(len(rb.buckets))
+	if index < 0 {
+		index += int64(len(rb.buckets))
+	}
+	b := &rb.buckets[index]
+	if b.second != second {
+		*b = budgetBucket{second: second}
+	}
+	return b
+}
+
+//the tokens that are left within the TTL until the given second
+func (rb *RetryBudget) balance(second int64) float64 {
+	var successes, retries int
+	for _, b := range rb.buckets {
+		if b.second > second-int64(len(rb.buckets)) && b.second <= second {
+			successes += b.successes
+			retries += b.retries
+		}
+	}
+	reserved := float64(rb.settings.MinPerSecond * len(rb.buckets))
+	return reserved + rb.settings.Ratio*float64(successes) - float64(retries)
+}
@@ -0,0 +1,78 @@
+package gover
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestRetryBudgetConfig(t *testing.T) {
+	for _, settings := range []RetryBudgetConfig{
+		{},
+		{Ratio: -0.1},
+		{MinPerSecond: -1},
+		{Ratio: 0.1, TTL: time.Millisecond},
+	} {
+		_, err := NewRetryBudget(settings)
+		assert.Error(t, err, settings)
+	}
+
+	_, err := New(func(ctx context.Context) error { return nil }, WithRetryBudget(nil))
+	assert.Error(t, err)
+}
+
+func TestRetryBudget(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	budget, err := NewRetryBudget(RetryBudgetConfig{Ratio: 0.5, MinPerSecond: 1, TTL: time.Second * 2, Clock: clock})
+	assert.NoError(t, err)
+
+	//the minimum is reserved for the whole TTL
+	assert.Equal(t, 2, budget.Available())
+	assert.Equal(t, true, budget.withdraw())
+	assert.Equal(t, true, budget.withdraw())
+	assert.Equal(t, false, budget.withdraw())
+
+	//every success allows half a retry
+	budget.deposit()
+	assert.Equal(t, 0, budget.Available())
+	budget.deposit()
+	assert.Equal(t, 1, budget.Available())
+	assert.Equal(t, true, budget.withdraw())
+	assert.Equal(t, false, budget.withdraw())
+
+	//the old retries expire after the TTL
+	clock.Advance(time.Second)
+	assert.Equal(t, 0, budget.Available())
+	clock.Advance(time.Second)
+	assert.Equal(t, 2, budget.Available())
+}
+
+func TestGoverRetryBudget(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	budget, _ := NewRetryBudget(RetryBudgetConfig{MinPerSecond: 1, TTL: time.Second, Clock: clock})
+
+	tryNum := 0
+	job := func(ctx context.Context) error {
+		tryNum++
+		return fmt.Errorf("try %d", tryNum)
+	}
+
+	//only a single retry is in the budget
+	gover, err := New(job, WithRetryBudget(budget), WithMaxRetry(5), WithRetryInterval(0))
+	assert.NoError(t, err)
+	err = gover.Run(context.Background())
+	assert.Equal(t, true, errors.Is(err, ErrRetryBudgetExhausted))
+	assert.Equal(t, 2, tryNum)
+
+	//the other govers with the same budget can't retry either
+	other, _ := New(job, WithRetryBudget(budget), WithMaxRetry(5), WithRetryInterval(0))
+	err = other.Run(context.Background())
+	assert.Equal(t, true, errors.Is(err, ErrRetryBudgetExhausted))
+	assert.Equal(t, 3, tryNum)
+	var retryErr *RetryError
+	assert.Equal(t, true, errors.As(err, &retryErr))
+	assert.Equal(t, 1, len(retryErr.Attempts))
+}
@@ -0,0 +1,117 @@
+//retry policy decides whether gover should retry the error of the job
+//unlike NoRetryConditions it can look into the wrapped errors with errors.Is and errors.As
+package gover
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+//decide whether the error of the attempt (starting from 1) should be retried
+//the delay is the waiting duration before the next attempt, 0 means the regular backoff or retry interval
+type RetryPolicy func(attempt int, err error) (retry bool, delay time.Duration)
+
+//retry only the errors that match one of the targets (errors.Is)
+func RetryOn(targets ...error) RetryPolicy {
+	return func(_ int, err error) (bool, time.Duration) {
+		return isAny(err, targets), 0
+	}
+}
+
+//retry all errors except the ones that match one of the targets (errors.Is)
+func NoRetryOn(targets ...error) RetryPolicy {
+	return func(_ int, err error) (bool, time.Duration) {
+		return !isAny(err, targets), 0
+	}
+}
+
+//retry only the errors that contain an error of type T (errors.As)
+//e.g. RetryOnType[*net.OpError]()
+func RetryOnType[T error]() RetryPolicy {
+	return func(_ int, err error) (bool, time.Duration) {
+		var target T
+		return errors.As(err, &target), 0
+	}
+}
+
+//retry all errors except the ones that contain an error of type T (errors.As)
+func NoRetryOnType[T error]() RetryPolicy {
+	return func(_ int, err error) (bool, time.Duration) {
+		var target T
+		return !errors.As(err, &target), 0
+	}
+}
+
+//check whether the error matches any of the targets
+func isAny(err error, targets []error) bool {
+	for _, target := range targets {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+///////////////////////////////
+///// PERMANENT ERROR ////////
+/////////////////////////////
+
+//error that is never retried, regardless of the retry configuration
+type permanentError struct {
+	err error
+}
+
+func (pe *permanentError) Error() string { return pe.err.Error() }
+func (pe *permanentError) Unwrap() error { return pe.err }
+
+//mark the error as permanent, so gover stops retrying and returns it immediately
+//the original error can still be found with errors.Is and errors.As
+//return nil if the error is nil
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err}
+}
+
+//check whether the error or any error it wraps is marked as permanent
+func IsPermanent(err error) bool {
+	var pe *permanentError
+	return errors.As(err, &pe)
+}
+
+///////////////////////////////
+///// RETRY AFTER ERROR //////
+/////////////////////////////
+
+//error with the waiting duration requested by the job
+type retryAfterError struct {
+	err   error
+	delay time.Duration
+}
+
+func (re *retryAfterError) Error() string {
+	return fmt.Sprintf("%s (retry after %s)", re.err, re.delay)
+}
+
+func (re *retryAfterError) Unwrap() error { return re.err }
+
+//ask gover to wait for the delay before the next attempt (e.g. from the Retry-After header)
+//the delay replaces the backoff and the delay of the retry policy, but the error is still retried only if it's allowed
+//return nil if the error is nil
+func RetryAfter(err error, delay time.Duration) error {
+	if err == nil {
+		return nil
+	}
+	return &retryAfterError{err: err, delay: delay}
+}
+
+//get the delay requested by the job if there's any
+func retryAfter(err error) (time.Duration, bool) {
+	var re *retryAfterError
+	if errors.As(err, &re) && re.delay > 0 {
+		return re.delay, true
+	}
+	return 0, false
+}
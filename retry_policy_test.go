@@ -0,0 +1,139 @@
+package gover
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type hungryError struct {
+	name string
+}
+
+func (he *hungryError) Error() string { return he.name + " is hungry" }
+
+func TestRetryPolicyHelpers(t *testing.T) {
+	errTemporary := errors.New("temporary")
+	errFatal := errors.New("fatal")
+	wrapped := fmt.Errorf("wrapped: %w", errTemporary)
+	hungry := fmt.Errorf("wrapped: %w", &hungryError{"Moritz"})
+
+	retry, delay := RetryOn(errTemporary)(1, wrapped)
+	assert.Equal(t, true, retry)
+	assert.Equal(t, time.Duration(0), delay)
+	retry, _ = RetryOn(errTemporary)(1, errFatal)
+	assert.Equal(t, false, retry)
+
+	retry, _ = NoRetryOn(errFatal)(1, wrapped)
+	assert.Equal(t, true, retry)
+	retry, _ = NoRetryOn(errTemporary, errFatal)(1, errFatal)
+	assert.Equal(t, false, retry)
+
+	retry, _ = RetryOnType[*hungryError]()(1, hungry)
+	assert.Equal(t, true, retry)
+	retry, _ = RetryOnType[*hungryError]()(1, wrapped)
+	assert.Equal(t, false, retry)
+	retry, _ = NoRetryOnType[*hungryError]()(1, hungry)
+	assert.Equal(t, false, retry)
+
+	assert.Nil(t, Permanent(nil))
+	permanent := fmt.Errorf("wrapped: %w", Permanent(errFatal))
+	assert.Equal(t, true, IsPermanent(permanent))
+	assert.Equal(t, false, IsPermanent(wrapped))
+	assert.Equal(t, true, errors.Is(permanent, errFatal))
+	assert.Equal(t, "wrapped: fatal", permanent.Error())
+
+	assert.Nil(t, RetryAfter(nil, time.Second))
+	retryAfterErr := RetryAfter(errTemporary, time.Minute)
+	assert.Equal(t, true, errors.Is(retryAfterErr, errTemporary))
+	assert.Equal(t, "temporary (retry after 1m0s)", retryAfterErr.Error())
+	requested, ok := retryAfter(fmt.Errorf("wrapped: %w", retryAfterErr))
+	assert.Equal(t, true, ok)
+	assert.Equal(t, time.Minute, requested)
+	_, ok = retryAfter(errTemporary)
+	assert.Equal(t, false, ok)
+}
+
+func TestGoverPermanentError(t *testing.T) {
+	errFatal := errors.New("fatal")
+	tryNum := 0
+	job := func(ctx context.Context) error {
+		tryNum++
+		return Permanent(errFatal)
+	}
+
+	gover, _ := New(job, WithTimeout(time.Second))
+	gover.MaxRetry = 5
+	err := gover.Run(context.Background())
+	assert.Equal(t, 1, tryNum)
+	assert.Equal(t, true, errors.Is(err, errFatal))
+	assert.Equal(t, true, errors.Is(err, ErrNonRetryable))
+}
+
+func TestGoverRetryPolicy(t *testing.T) {
+	errTemporary := errors.New("temporary")
+	errFatal := errors.New("fatal")
+	tryNum := 0
+	job := func(ctx context.Context) error {
+		tryNum++
+		if tryNum < 3 {
+			return errTemporary
+		}
+		return errFatal
+	}
+
+	//only the temporary error is retried
+	gover, _ := New(job, WithTimeout(time.Second))
+	gover.MaxRetry = 5
+	gover.RetryPolicy = RetryOn(errTemporary)
+	err := gover.Run(context.Background())
+	assert.Equal(t, true, errors.Is(err, errFatal))
+	assert.Equal(t, true, errors.Is(err, ErrNonRetryable))
+	assert.Equal(t, 3, tryNum)
+
+	//the policy doesn't allow more than the maximum retry
+	tryNum = 0
+	gover.MaxRetry = 1
+	err = gover.Run(context.Background())
+	assert.Equal(t, true, errors.Is(err, ErrMaxRetry))
+	assert.Equal(t, true, errors.Is(err, errTemporary))
+	assert.Equal(t, 2, tryNum)
+}
+
+func TestGoverRetryDelay(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	start := clock.Now()
+
+	var tries []time.Time
+	job := func(ctx context.Context) error {
+		tries = append(tries, clock.Now())
+		switch len(tries) {
+		case 1:
+			return errors.New("busy")
+		case 2:
+			return RetryAfter(errors.New("too many requests"), time.Hour)
+		}
+		return nil
+	}
+
+	gover, _ := New(job, WithTimeout(time.Hour*24), WithClock(clock))
+	gover.MaxRetry = 3
+	gover.Backoff = ConstantBackoff(time.Second)
+	//the policy asks for 1 minute, the job for 1 hour afterwards
+	gover.RetryPolicy = func(attempt int, err error) (bool, time.Duration) {
+		return true, time.Minute * time.Duration(attempt)
+	}
+
+	result := make(chan error, 1)
+	go func() { result <- gover.Run(context.Background()) }()
+
+	for _, delay := range []time.Duration{time.Minute, time.Hour} {
+		clock.BlockUntil(2)
+		clock.Advance(delay)
+	}
+	assert.NoError(t, <-result)
+	assert.Equal(t, []time.Time{start, start.Add(time.Minute), start.Add(time.Minute + time.Hour)}, tries)
+}
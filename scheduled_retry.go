@@ -0,0 +1,93 @@
+//retry of the scheduled jobs
+//each run of a gotermin can be wrapped in a gover, so a failed job is retried
+//the retries can only happen until the next run is due, i.e. within the window of the run
+package gover
+
+import (
+	"fmt"
+	"time"
+)
+
+//the retry configuration of the scheduled jobs
+//the fields have the same meaning as the ones in Gover
+type RetryConfig struct {
+	//number of maximum retry of each run
+	MaxRetry int
+	//keywords for error message that's not supposed to be retried
+	NoRetryConditions []string
+	//interval between each retrial
+	//if it's 0 the job will be retried almost immediately
+	RetryInterval time.Duration
+	//decide the waiting duration before each retrial, RetryInterval is ignored if it's set
+	Backoff BackoffStrategy
+	//decide whether the error should be retried, see Gover.RetryPolicy
+	RetryPolicy RetryPolicy
+	//timeout for each retrial, WithAttemptTimeout has the priority
+	//if it's 0 each retrial can last until the next run is due
+	JobInterval time.Duration
+}
+
+//make sure that the configuration can be used by gover
+func (rc RetryConfig) validate() error {
+	if rc.MaxRetry < 0 {
+		return fmt.Errorf("Invalid maximum number of retry: %d", rc.MaxRetry)
+	}
+
+	for _, duration := range []time.Duration{rc.RetryInterval, rc.JobInterval} {
+		if duration < 0 {
+			return fmt.Errorf("Invalid retry duration: %s", duration)
+		}
+	}
+	return nil
+}
+
+//retry the failed jobs with the configuration
+//for the scheduled jobs the deadline of all retrials is the next run of the schedule
+//it's the same as giving WithMaxRetry, WithBackoff etc. separately
+func WithRetry(retry RetryConfig) Option {
+	return func(c *config) error {
+		if err := retry.validate(); err != nil {
+			return err
+		}
+		//copy it, so the following options can't change the one of the other configurations
+		copied := retry
+		c.retry = &copied
+		return nil
+	}
+}
+
+//create the gover for a single run, the deadline is the end of the window
+//return nil if the job should not be retried
+func (gt *Gotermin) newRetry(deadline time.Time) *Gover {
+	if gt.cfg.retry == nil {
+		return nil
+	}
+
+	//without any window there's no time to retry
+	if deadline.IsZero() {
+		return nil
+	}
+
+	//the panics are counted by the gotermin before they are handed over
+	g := newGover(gt.job(), gt.cfg)
+	g.Deadline = deadline
+	g.panicHandler = gt.handlePanic
+	g.key = gt.key
+	g.waitForJobs = true
+	return g
+}
+
+//copy the configuration into the gover
+//the durations are given as the typed settings instead of the string fields of gover
+func (rc *RetryConfig) apply(g *Gover) {
+	g.MaxRetry = rc.MaxRetry
+	g.NoRetryConditions = rc.NoRetryConditions
+	g.Backoff = rc.Backoff
+	if g.Backoff == nil && rc.RetryInterval > 0 {
+		g.Backoff = ConstantBackoff(rc.RetryInterval)
+	}
+	g.RetryPolicy = rc.RetryPolicy
+	if g.attemptTimeout == 0 {
+		g.attemptTimeout = rc.JobInterval
+	}
+}
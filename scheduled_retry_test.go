@@ -0,0 +1,181 @@
+package gover
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestRetryOption(t *testing.T) {
+	invalids := []RetryConfig{
+		{MaxRetry: -1},
+		{RetryInterval: -time.Second},
+		{JobInterval: -time.Second},
+	}
+	for _, retry := range invalids {
+		_, err := NewCrontab(globalTimeLoc, WithRetry(retry))
+		assert.Error(t, err)
+	}
+
+	crontab, err := NewCrontab(globalTimeLoc, WithRetry(RetryConfig{MaxRetry: 3, RetryInterval: time.Minute}))
+	assert.NoError(t, err)
+	assert.NoError(t, crontab.RegisterNewHourly("foo", randomFunc, "30"))
+	assert.NoError(t, crontab.RegisterNewHourly("bar", randomFunc, "30", WithRetry(RetryConfig{JobInterval: time.Minute * 5})))
+	assert.Equal(t, 3, crontab.cronjobs["foo"].cfg.retry.MaxRetry)
+	assert.Equal(t, time.Minute*5, crontab.cronjobs["bar"].cfg.retry.JobInterval)
+
+	//the options of gover can be combined with the crontab default
+	assert.NoError(t, crontab.RegisterNewHourly("baz", randomFunc, "30", WithMaxRetry(5), WithAttemptTimeout(time.Minute)))
+	assert.Equal(t, 5, crontab.cronjobs["baz"].cfg.retry.MaxRetry)
+	assert.Equal(t, time.Minute, crontab.cronjobs["baz"].cfg.attemptTimeout)
+	assert.NoError(t, crontab.RegisterNewHourly("qux", randomFunc, "30"))
+	assert.Equal(t, 3, crontab.cronjobs["qux"].cfg.retry.MaxRetry)
+
+	//the retry is enabled by the option alone
+	gt, _ := NewHourly(randomFunc, "30", globalTimeLoc, WithMaxRetry(1))
+	assert.Equal(t, 1, gt.cfg.retry.MaxRetry)
+
+	//the options that only change the retries can't be used without them
+	cb, _ := NewCircuitBreaker(CircuitBreakerConfig{ConsecutiveFailures: 3, CoolDown: time.Minute})
+	_, err = NewDaily(randomFunc, "0200", globalTimeLoc, WithCircuitBreaker(cb))
+	assert.Equal(t, "WithCircuitBreaker can't be used by the scheduler without the retry, see WithRetry", err.Error())
+	_, err = NewDaily(randomFunc, "0200", globalTimeLoc, WithHedging(time.Second, 2))
+	assert.Error(t, err)
+	_, err = NewDaily(randomFunc, "0200", globalTimeLoc, WithAttemptTimeout(time.Minute))
+	assert.Error(t, err)
+	_, err = NewDaily(randomFunc, "0200", globalTimeLoc, WithAttemptTimeout(time.Minute), WithMaxRetry(1))
+	assert.NoError(t, err)
+
+	//the default of the crontab is checked once the retry is known
+	crontab, err = NewCrontab(globalTimeLoc, WithHooks(Hooks{}))
+	assert.NoError(t, err)
+	assert.Error(t, crontab.RegisterNewHourly("foo", randomFunc, "30"))
+	assert.NoError(t, crontab.RegisterNewHourly("foo", randomFunc, "30", WithRetry(RetryConfig{MaxRetry: 1})))
+}
+
+func TestScheduledRetry(t *testing.T) {
+	start := time.Date(2016, 11, 4, 2, 0, 0, 0, globalTimeLoc)
+	clock := NewFakeClock(start)
+	panics := make(chan error, 10)
+	crontab, _ := NewCrontab(globalTimeLoc, WithClock(clock), WithPanicHandler(func(err error) { panics <- err }))
+
+	//the job panics first, then fails and only succeeds on the third try
+	deadlines := make(chan time.Time, 10)
+	tryNum := 0
+	job := func(ctx context.Context) error {
+		tryNum++
+		deadline, _ := ctx.Deadline()
+		deadlines <- deadline
+		switch tryNum {
+		case 1:
+			panic("hiss")
+		case 2:
+			return errors.New("no food")
+		}
+		return nil
+	}
+
+	err := crontab.RegisterNewCustomIntervalWithError("grumpy", job, time.Hour, WithRetry(RetryConfig{MaxRetry: 3, RetryInterval: time.Minute}))
+	assert.NoError(t, err)
+	assert.NoError(t, crontab.Start("grumpy"))
+
+	//every try has to be finished before the next run
+	assert.Equal(t, start.Add(time.Hour), <-deadlines)
+	assert.Equal(t, "Job grumpy panicked: hiss", (<-panics).Error())
+	for i := 0; i < 2; i++ {
+		//the loop, the context of the run, the deadline of the retry and the retry interval are waiting
+		clock.BlockUntil(4)
+		clock.Advance(time.Minute)
+		assert.Equal(t, start.Add(time.Hour), <-deadlines)
+	}
+
+	gt, _ := crontab.GetCronjob("grumpy")
+	eventually(t, func() bool { return !gt.Stats().LastSuccess.IsZero() })
+	stats := gt.Stats()
+	assert.Equal(t, start.Add(time.Minute*2), stats.LastSuccess)
+	assert.Equal(t, int64(1), stats.Runs)
+	assert.Equal(t, int64(1), stats.Panics)
+	assert.Equal(t, int64(0), stats.Failures)
+
+	assert.NoError(t, crontab.Shutdown(context.Background()))
+}
+
+func TestScheduledRetryNoRetryConditions(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	tryNum := 0
+	job := func(ctx context.Context) error {
+		tryNum++
+		return errors.New("no food")
+	}
+
+	gt, err := NewCustomIntervalWithError(job, time.Hour, globalTimeLoc, WithClock(clock),
+		WithRetry(RetryConfig{MaxRetry: 3, NoRetryConditions: []string{"food"}}))
+	assert.NoError(t, err)
+	assert.NoError(t, gt.Start())
+
+	eventually(t, func() bool { return gt.Stats().Failures == 1 })
+	lastErr := gt.Stats().LastError
+	assert.Equal(t, true, errors.Is(lastErr, ErrNonRetryable))
+	assert.Equal(t, "Error is not retryable, it contains keyword: food (attempts: 1): no food", lastErr.Error())
+	assert.Equal(t, 1, tryNum)
+	assert.NoError(t, gt.Shutdown(context.Background()))
+}
+
+func TestScheduledRetryWaitsForJob(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+
+	//the job ignores the end of its window and the cancellation
+	release := make(chan struct{})
+	gt, err := NewCustomInterval(func(ctx context.Context) { <-release }, time.Hour, globalTimeLoc, WithClock(clock),
+		WithRetry(RetryConfig{MaxRetry: 1}), WithOverlapPolicy(OverlapSkip))
+	assert.NoError(t, err)
+	assert.NoError(t, gt.Start())
+	eventually(t, func() bool { return gt.Stats().Runs == 1 })
+
+	//the run is still going on, so the next one is skipped
+	//the loop and the deadline of the retry are waiting
+	clock.BlockUntil(2)
+	clock.Advance(time.Hour)
+	eventually(t, func() bool { return gt.Stats().Skipped == 1 })
+	assert.Equal(t, int64(1), gt.Stats().Runs)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, gt.Shutdown(ctx))
+
+	close(release)
+	assert.NoError(t, gt.Shutdown(context.Background()))
+	assert.Equal(t, int64(1), gt.Stats().Failures)
+}
+
+func TestScheduledRetryHedging(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+
+	//the first attempt of the run is too slow, the hedged one wins
+	cancelled := make(chan error, 1)
+	job := func(ctx context.Context) error {
+		attempt, _ := AttemptFromContext(ctx)
+		if attempt.Number == 1 {
+			<-ctx.Done()
+			cancelled <- ctx.Err()
+			return ctx.Err()
+		}
+		return nil
+	}
+
+	gt, err := NewCustomIntervalWithError(job, time.Hour, globalTimeLoc, WithClock(clock),
+		WithMaxRetry(1), WithHedging(time.Second, 2))
+	assert.NoError(t, err)
+	assert.NoError(t, gt.Start())
+	eventually(t, func() bool { return gt.Stats().Runs == 1 })
+
+	//the loop, the deadline of the retry and the hedging delay are waiting
+	clock.BlockUntil(3)
+	clock.Advance(time.Second)
+	assert.Equal(t, context.Canceled, <-cancelled)
+	eventually(t, func() bool { return !gt.Stats().LastSuccess.IsZero() })
+	assert.Equal(t, int64(0), gt.Stats().Failures)
+	assert.NoError(t, gt.Shutdown(context.Background()))
+}